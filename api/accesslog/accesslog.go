@@ -0,0 +1,121 @@
+package accesslog
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//label of requests which did not match any route
+const unmatchedRoute = "unmatched"
+
+//Options selects the request headers written to the access log and the values redacted from it
+type Options struct {
+	//Headers are logged with each request when present
+	Headers []string
+	//RedactHeaders are logged with their value replaced
+	RedactHeaders []string
+	//RedactQuery are query parameters of the logged path whose value is replaced
+	RedactQuery []string
+}
+
+//AccessLogMiddleware writes one structured entry per request once it is served, through the
+//request logger so the entry carries the request ID, route, client IP, tenant, principal and trace fields
+func AccessLogMiddleware(options Options) gin.HandlerFunc {
+	redactHeaders := canonicalSet(options.RedactHeaders)
+	redactQuery := make(map[string]bool, len(options.RedactQuery))
+	for _, param := range options.RedactQuery {
+		redactQuery[param] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := c.Writer.Status()
+		fields := logging.ApiLoggerFields{
+			constants.AccessMethod:  c.Request.Method,
+			constants.AccessPath:    redactedPath(c.Request.URL, redactQuery),
+			constants.AccessStatus:  status,
+			constants.AccessLatency: float64(time.Since(start).Microseconds()) / 1000,
+			constants.AccessBytes:   bodySize(c),
+		}
+		if tickets, ok := c.Get(constants.TICKET_COUNT_KEY); ok {
+			fields[constants.AccessTickets] = tickets
+		}
+		if errorCode, ok := c.Get(constants.ERROR_CODE_KEY); ok {
+			fields[constants.AccessErrorCode] = fmt.Sprint(errorCode)
+		}
+		if headers := loggedHeaders(c.Request.Header, options.Headers, redactHeaders); len(headers) > 0 {
+			fields[constants.AccessHeaders] = headers
+		}
+
+		logger := logging.GetLogger(c).WithFields(fields)
+		message := fmt.Sprintf("%s %s %d", c.Request.Method, route, status)
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error(message)
+		case status >= http.StatusBadRequest:
+			logger.Warn(message)
+		default:
+			logger.Info(message)
+		}
+	}
+}
+
+//bodySize is the number of bytes of the response body, 0 when nothing was written
+func bodySize(c *gin.Context) int {
+	if size := c.Writer.Size(); size > 0 {
+		return size
+	}
+	return 0
+}
+
+//redactedPath keeps the raw query of the request except for the values of the redacted parameters
+func redactedPath(u *url.URL, redactQuery map[string]bool) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		key := strings.SplitN(param, "=", 2)[0]
+		if name, err := url.QueryUnescape(key); err == nil && redactQuery[name] {
+			params[i] = key + "=" + constants.Redacted
+		}
+	}
+	return u.Path + "?" + strings.Join(params, "&")
+}
+
+//loggedHeaders keeps the configured headers of the request, redacted ones are logged whenever present
+func loggedHeaders(header http.Header, names []string, redact map[string]bool) map[string]string {
+	headers := make(map[string]string)
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	for name := range redact {
+		if header.Get(name) != "" {
+			headers[name] = constants.Redacted
+		}
+	}
+	return headers
+}
+
+func canonicalSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[http.CanonicalHeaderKey(name)] = true
+	}
+	return set
+}
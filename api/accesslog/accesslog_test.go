@@ -0,0 +1,102 @@
+package accesslog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccessLogTestSuite struct {
+	suite.Suite
+	hook   *test.Hook
+	router *gin.Engine
+}
+
+func TestAccessLog(t *testing.T) {
+	suite.Run(t, new(AccessLogTestSuite))
+}
+
+func (suite *AccessLogTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+	suite.hook = test.NewLocal(logrus.StandardLogger())
+}
+
+func (suite *AccessLogTestSuite) SetupTest() {
+	suite.hook.Reset()
+	suite.router = gin.New()
+	suite.router.Use(requestid.New())
+	suite.router.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()))
+	suite.router.Use(AccessLogMiddleware(Options{
+		Headers:       []string{"user-agent"},
+		RedactHeaders: []string{constants.APIKeyHeader},
+		RedactQuery:   []string{"token"},
+	}))
+	suite.router.POST("/track/:id", func(c *gin.Context) {
+		c.Set(constants.TICKET_COUNT_KEY, 4)
+		c.String(http.StatusOK, "done")
+	})
+	suite.router.POST("/fail", func(c *gin.Context) {
+		errors.AbortWithErrorResponse(c, errors.ErrInvalidTicket)
+	})
+}
+
+func (suite *AccessLogTestSuite) serve(method, target string, header http.Header) *logrus.Entry {
+	req, _ := http.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	suite.router.ServeHTTP(httptest.NewRecorder(), req)
+	suite.Require().Len(suite.hook.AllEntries(), 1)
+	return suite.hook.LastEntry()
+}
+
+func (suite *AccessLogTestSuite) TestLogsOneStructuredEntryPerRequest() {
+	entry := suite.serve(http.MethodPost, "/track/7?token=secret&page=2", http.Header{
+		"User-Agent": {"curl/7.79"},
+		"X-Api-Key":  {"key"},
+		"Cookie":     {"session=1"},
+	})
+
+	suite.Equal(logrus.InfoLevel, entry.Level)
+	suite.Equal("POST /track/:id 200", entry.Message)
+	suite.Equal(http.MethodPost, entry.Data[constants.AccessMethod])
+	suite.Equal("/track/:id", entry.Data[constants.Route])
+	suite.Equal("/track/7?token=[REDACTED]&page=2", entry.Data[constants.AccessPath])
+	suite.Equal(http.StatusOK, entry.Data[constants.AccessStatus])
+	suite.Equal(4, entry.Data[constants.AccessBytes])
+	suite.Equal(4, entry.Data[constants.AccessTickets])
+	suite.NotEmpty(entry.Data[constants.ReqID])
+	suite.Contains(entry.Data, constants.AccessLatency)
+	suite.Contains(entry.Data, constants.ClientIP)
+	suite.NotContains(entry.Data, constants.AccessErrorCode)
+	suite.Equal(map[string]string{
+		"User-Agent": "curl/7.79",
+		"X-Api-Key":  constants.Redacted,
+	}, entry.Data[constants.AccessHeaders])
+}
+
+func (suite *AccessLogTestSuite) TestLogsErrorCodeOfFailedRequests() {
+	entry := suite.serve(http.MethodPost, "/fail", nil)
+
+	suite.Equal(logrus.WarnLevel, entry.Level)
+	suite.Equal(errors.InvalidTicket, entry.Data[constants.AccessErrorCode])
+	suite.NotContains(entry.Data, constants.AccessHeaders)
+}
+
+func (suite *AccessLogTestSuite) TestLogsUnmatchedRoutes() {
+	entry := suite.serve(http.MethodGet, "/unknown", nil)
+
+	suite.Equal("GET unmatched 404", entry.Message)
+	suite.NotContains(entry.Data, constants.Route)
+	suite.Equal(http.StatusNotFound, entry.Data[constants.AccessStatus])
+	suite.Equal(0, entry.Data[constants.AccessBytes])
+}
@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminTestSuite struct {
+	suite.Suite
+	logSettings LogSettings
+	router      *gin.Engine
+}
+
+func TestAdmin(t *testing.T) {
+	suite.Run(t, new(AdminTestSuite))
+}
+
+func (suite *AdminTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	var err error
+	suite.logSettings, err = NewLogSettings(logging.NewLoggerEntry(), logging.INFO, constants.TEXT)
+	suite.Require().Nil(err)
+
+	adminController := NewAdminController(suite.logSettings)
+	suite.router = gin.New()
+	suite.router.GET("/admin/log", adminController.GetLogSettings)
+	suite.router.PUT("/admin/log", adminController.UpdateLogSettings)
+	suite.router.DELETE("/admin/log", adminController.ResetLogSettings)
+}
+
+func (suite *AdminTestSuite) TearDownTest() {
+	suite.logSettings.Reset()
+}
+
+func (suite *AdminTestSuite) call(method, body string) (*httptest.ResponseRecorder, LogState) {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/admin/log", bytes.NewBufferString(body))
+	suite.router.ServeHTTP(recorder, req)
+	var state LogState
+	json.Unmarshal(recorder.Body.Bytes(), &state)
+	return recorder, state
+}
+
+func (suite *AdminTestSuite) TestNewLogSettingsAppliesDefaults() {
+	suite.Equal(logrus.InfoLevel, logrus.GetLevel())
+	suite.Equal(LogState{Level: logging.INFO, Format: constants.TEXT}, suite.logSettings.Get())
+}
+
+func (suite *AdminTestSuite) TestOverrideKeepsUnsetValues() {
+	state, err := suite.logSettings.Override(logging.DEBUG, "", 0)
+
+	suite.Nil(err)
+	suite.Equal(LogState{Level: logging.DEBUG, Format: constants.TEXT}, state)
+	suite.Equal(logrus.DebugLevel, logrus.GetLevel())
+}
+
+func (suite *AdminTestSuite) TestInvalidOverrideIsRejected() {
+	_, err := suite.logSettings.Override("loud", "", 0)
+	suite.NotNil(err)
+	_, err = suite.logSettings.Override("", "xml", 0)
+	suite.NotNil(err)
+
+	suite.Equal(logrus.InfoLevel, logrus.GetLevel())
+	suite.Equal(LogState{Level: logging.INFO, Format: constants.TEXT}, suite.logSettings.Get())
+}
+
+func (suite *AdminTestSuite) TestOverrideRevertsAfterTTL() {
+	state, err := suite.logSettings.Override(logging.DEBUG, constants.JSON, 20*time.Millisecond)
+
+	suite.Nil(err)
+	suite.NotNil(state.ExpiresAt)
+	suite.Eventually(func() bool {
+		return suite.logSettings.Get() == LogState{Level: logging.INFO, Format: constants.TEXT}
+	}, time.Second, 5*time.Millisecond)
+	suite.Equal(logrus.InfoLevel, logrus.GetLevel())
+}
+
+func (suite *AdminTestSuite) TestNewOverrideCancelsPreviousTTL() {
+	suite.logSettings.Override(logging.DEBUG, "", 20*time.Millisecond)
+	suite.logSettings.Override(logging.TRACE, "", 0)
+
+	time.Sleep(50 * time.Millisecond)
+
+	suite.Equal(logging.TRACE, suite.logSettings.Get().Level)
+}
+
+func (suite *AdminTestSuite) TestSetDefaultsKeepsActiveOverride() {
+	suite.logSettings.Override(logging.DEBUG, "", 0)
+
+	suite.Nil(suite.logSettings.SetDefaults(logging.WARN, constants.TEXT))
+	suite.Equal(logrus.DebugLevel, logrus.GetLevel())
+
+	suite.logSettings.Reset()
+	suite.Equal(logrus.WarnLevel, logrus.GetLevel())
+	suite.Nil(suite.logSettings.SetDefaults(logging.INFO, constants.TEXT))
+}
+
+func (suite *AdminTestSuite) TestUpdateLogSettingsSuccessfully() {
+	recorder, state := suite.call(http.MethodPut, `{"level":"debug","format":"json","ttl":"15m"}`)
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal(logging.DEBUG, state.Level)
+	suite.Equal(constants.JSON, state.Format)
+	suite.NotNil(state.ExpiresAt)
+
+	recorder, state = suite.call(http.MethodGet, "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal(logging.DEBUG, state.Level)
+}
+
+func (suite *AdminTestSuite) TestUpdateLogSettingsRejectsInvalidRequests() {
+	for _, body := range []string{`{"level":`, `{"level":"loud"}`, `{"level":"debug","ttl":"soon"}`, `{"level":"debug","ttl":"-1m"}`} {
+		recorder, _ := suite.call(http.MethodPut, body)
+		suite.Equal(http.StatusBadRequest, recorder.Code, body)
+	}
+	suite.Equal(logrus.InfoLevel, logrus.GetLevel())
+}
+
+func (suite *AdminTestSuite) TestResetLogSettings() {
+	suite.call(http.MethodPut, `{"level":"debug"}`)
+
+	recorder, state := suite.call(http.MethodDelete, "")
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal(LogState{Level: logging.INFO, Format: constants.TEXT}, state)
+}
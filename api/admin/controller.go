@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/dto"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+type AdminController interface {
+	GetLogSettings(c *gin.Context)
+	UpdateLogSettings(c *gin.Context)
+	ResetLogSettings(c *gin.Context)
+}
+
+type adminController struct {
+	logSettings LogSettings
+}
+
+func NewAdminController(logSettings LogSettings) AdminController {
+	return adminController{
+		logSettings: logSettings,
+	}
+}
+
+//GetLogSettings returns the log level and format in use
+func (ac adminController) GetLogSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, ac.logSettings.Get())
+}
+
+//UpdateLogSettings overrides the log level and format, until the TTL expires when one is given
+func (ac adminController) UpdateLogSettings(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.Interface, "AdminController").
+		WithField(constants.Method, "UpdateLogSettings")
+
+	settings := new(dto.LogSettings)
+	if err := c.ShouldBindJSON(settings); err != nil {
+		logger.Errorf("ShouldBindJSON - %s", err.Error())
+		errors.AbortWithErrorResponse(c, errors.ErrBadRequest)
+		return
+	}
+
+	var ttl time.Duration
+	if settings.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(settings.TTL); err != nil || ttl <= 0 {
+			logger.Errorf("ParseDuration - invalid ttl %q", settings.TTL)
+			errors.AbortWithErrorResponse(c, errors.ErrBadRequest)
+			return
+		}
+	}
+
+	state, err := ac.logSettings.Override(settings.Level, settings.Format, ttl)
+	if err != nil {
+		logger.Errorf("Override - %s", err.Error())
+		errors.AbortWithErrorResponse(c, errors.ErrBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+	logger.Infof("Log settings overridden with level %s and format %s", state.Level, state.Format)
+}
+
+//ResetLogSettings drops any override and goes back to the configured log level and format
+func (ac adminController) ResetLogSettings(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.Interface, "AdminController").
+		WithField(constants.Method, "ResetLogSettings")
+
+	state := ac.logSettings.Reset()
+
+	c.JSON(http.StatusOK, state)
+	logger.Infof("Log settings reset to level %s and format %s", state.Level, state.Format)
+}
+
@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//LogState is the level and format used by the API logger. ExpiresAt is set while a temporary override is active
+type LogState struct {
+	Level     string     `json:"level"`
+	Format    string     `json:"format"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+//LogSettings changes the level and format of the API logger at runtime. Overrides replace the
+//defaults from the config, forever or until their TTL expires
+type LogSettings interface {
+	Get() LogState
+	Override(level, format string, ttl time.Duration) (LogState, error)
+	Reset() LogState
+	SetDefaults(level, format string) error
+}
+
+type logSettings struct {
+	mu       sync.Mutex
+	logger   logging.ApiLoggerEntry
+	defaults LogState
+	current  LogState
+	//overridden is set from Override until Reset or the TTL expires
+	overridden bool
+	timer      *time.Timer
+	now        func() time.Time
+}
+
+//NewLogSettings applies the default level and format to the logger
+func NewLogSettings(logger logging.ApiLoggerEntry, level, format string) (LogSettings, error) {
+	settings := &logSettings{
+		logger: logger,
+		now:    time.Now,
+	}
+	if err := settings.SetDefaults(level, format); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *logSettings) Get() LogState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+//Override applies the level and format, an empty one keeps its current value. A positive TTL
+//reverts the override to the defaults once it expires
+func (s *logSettings) Override(level, format string, ttl time.Duration) (LogState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := LogState{Level: s.current.Level, Format: s.current.Format}
+	if level != "" {
+		state.Level = level
+	}
+	if format != "" {
+		state.Format = format
+	}
+	if err := s.apply(state); err != nil {
+		return s.current, err
+	}
+
+	s.stopTimer()
+	if ttl > 0 {
+		expiresAt := s.now().Add(ttl)
+		state.ExpiresAt = &expiresAt
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() { s.expire(timer) })
+		s.timer = timer
+	}
+	s.current = state
+	s.overridden = true
+	return s.current, nil
+}
+
+//Reset drops the override and goes back to the defaults
+func (s *logSettings) Reset() LogState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revert()
+	return s.current
+}
+
+//SetDefaults replaces the defaults, they are applied right away unless an override is active
+func (s *logSettings) SetDefaults(level, format string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defaults := LogState{Level: level, Format: format}
+	if !s.overridden {
+		if err := s.apply(defaults); err != nil {
+			return err
+		}
+		s.current = defaults
+	} else if err := validate(defaults); err != nil {
+		return err
+	}
+	s.defaults = defaults
+	return nil
+}
+
+//expire reverts the override of the timer, unless another override replaced it in the meantime
+func (s *logSettings) expire(timer *time.Timer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != timer {
+		return
+	}
+	s.revert()
+	s.logger.WithField(constants.Interface, "LogSettings").
+		WithField(constants.Method, "expire").
+		Infof("Log override expired, level %s and format %s restored", s.current.Level, s.current.Format)
+}
+
+func (s *logSettings) revert() {
+	s.stopTimer()
+	//the defaults were applied once already so they are valid
+	_ = s.apply(s.defaults)
+	s.current = s.defaults
+	s.overridden = false
+}
+
+func (s *logSettings) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+func (s *logSettings) apply(state LogState) error {
+	if err := validate(state); err != nil {
+		return err
+	}
+	if err := s.logger.SetLevel(state.Level); err != nil {
+		return err
+	}
+	return s.logger.SetFormatter(state.Format)
+}
+
+func validate(state LogState) error {
+	if err := logging.ValidateFormat(state.Format); err != nil {
+		return err
+	}
+	switch state.Level {
+	case logging.PANIC, logging.FATAL, logging.ERROR, logging.WARN, logging.WARNING, logging.INFO, logging.PRINT, logging.DEBUG, logging.TRACE:
+		return nil
+	}
+	return fmt.Errorf("log level %q is not a valid level", state.Level)
+}
@@ -0,0 +1,109 @@
+package airports
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+)
+
+//Component is the name of the airport registry in the readiness probe
+const Component = "airports"
+
+//Registry tells which airport codes are known
+type Registry interface {
+	Known(code string) bool
+	Restricted() bool
+}
+
+type registry struct {
+	//codes is nil when every well formed code is accepted
+	codes map[string]struct{}
+}
+
+//NewRegistry returns a registry which knows every airport code
+func NewRegistry() Registry {
+	return &registry{}
+}
+
+//LoadRegistry reads the known airport codes from a file, one code per line.
+//Blank lines and lines starting with # are ignored
+func LoadRegistry(path string) (Registry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	codes := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		code := strings.TrimSpace(scanner.Text())
+		if code == "" || strings.HasPrefix(code, "#") {
+			continue
+		}
+		if len(code) != 3 || strings.ToUpper(code) != code {
+			return nil, fmt.Errorf("%s:%d: invalid airport code %q", path, line, code)
+		}
+		codes[code] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("%s has no airport codes", path)
+	}
+	return &registry{codes: codes}, nil
+}
+
+func (r *registry) Known(code string) bool {
+	if r.codes == nil {
+		return true
+	}
+	_, ok := r.codes[code]
+	return ok
+}
+
+func (r *registry) Restricted() bool {
+	return r.codes != nil
+}
+
+//Registries gives the registry of each tenant
+type Registries interface {
+	Get(tenantID tenant.ID) Registry
+}
+
+//TenantRegistries holds the registry of each tenant, tenants without a registry of their own use the
+//default registry. The registries can be replaced while requests use them
+type TenantRegistries struct {
+	current atomic.Value
+}
+
+//registries keeps the concrete type stored in the atomic.Value the same
+type registries struct {
+	defaults Registry
+	tenants  map[tenant.ID]Registry
+}
+
+func NewTenantRegistries(defaults Registry, tenants map[tenant.ID]Registry) *TenantRegistries {
+	r := &TenantRegistries{}
+	r.Replace(defaults, tenants)
+	return r
+}
+
+//Replace swaps all the registries in one step, requests see either the old or the new codes
+func (r *TenantRegistries) Replace(defaults Registry, tenants map[tenant.ID]Registry) {
+	r.current.Store(registries{defaults: defaults, tenants: tenants})
+}
+
+//Get returns the registry of the tenant
+func (r *TenantRegistries) Get(tenantID tenant.ID) Registry {
+	current := r.current.Load().(registries)
+	if registry, ok := current.tenants[tenantID]; ok {
+		return registry
+	}
+	return current.defaults
+}
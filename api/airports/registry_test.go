@@ -0,0 +1,60 @@
+package airports
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistryTestSuite struct {
+	suite.Suite
+	airportsFile string
+}
+
+func TestRegistry(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func (suite *RegistryTestSuite) SetupTest() {
+	suite.airportsFile = filepath.Join(suite.T().TempDir(), "airports.txt")
+}
+
+func (suite *RegistryTestSuite) TestLoadRegistrySkipsComments() {
+	suite.Require().Nil(ioutil.WriteFile(suite.airportsFile, []byte("# hubs\nSFO\n\nATL\n"), 0600))
+
+	registry, err := LoadRegistry(suite.airportsFile)
+
+	suite.Require().Nil(err)
+	suite.True(registry.Restricted())
+	suite.True(registry.Known("SFO"))
+	suite.False(registry.Known("EWR"))
+}
+
+func (suite *RegistryTestSuite) TestTenantRegistriesFallBackToDefaults() {
+	suite.Require().Nil(ioutil.WriteFile(suite.airportsFile, []byte("SFO\n"), 0600))
+	restricted, err := LoadRegistry(suite.airportsFile)
+	suite.Require().Nil(err)
+
+	registries := NewTenantRegistries(NewRegistry(), map[tenant.ID]Registry{"cargo": restricted})
+	suite.True(registries.Get("cargo").Restricted())
+	suite.False(registries.Get("cargo").Known("EWR"))
+	suite.False(registries.Get(tenant.Default).Restricted())
+	suite.True(registries.Get("passenger").Known("EWR"))
+}
+
+func (suite *RegistryTestSuite) TestTenantRegistriesUseReplacedRegistries() {
+	suite.Require().Nil(ioutil.WriteFile(suite.airportsFile, []byte("SFO\n"), 0600))
+	restricted, err := LoadRegistry(suite.airportsFile)
+	suite.Require().Nil(err)
+
+	registries := NewTenantRegistries(NewRegistry(), nil)
+	suite.False(registries.Get(tenant.Default).Restricted())
+
+	registries.Replace(restricted, map[tenant.ID]Registry{"cargo": NewRegistry()})
+	suite.True(registries.Get(tenant.Default).Restricted())
+	suite.False(registries.Get(tenant.Default).Known("EWR"))
+	suite.True(registries.Get("cargo").Known("EWR"))
+}
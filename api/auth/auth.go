@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+//Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Tenant  string
+	Method  string
+	Scopes  []string
+}
+
+//HasScopes reports whether the principal was granted every one of the scopes
+func (p Principal) HasScopes(scopes []string) bool {
+	for _, required := range scopes {
+		granted := false
+		for _, scope := range p.Scopes {
+			if scope == required {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+	RequiredScopes(route string) []string
+}
+
+type authenticator struct {
+	config *Config
+	jwks   *JWKS
+	now    func() time.Time
+}
+
+func NewAuthenticator(config *Config, jwks *JWKS) Authenticator {
+	return &authenticator{
+		config: config,
+		jwks:   jwks,
+		now:    time.Now,
+	}
+}
+
+//Authenticate accepts either a static key in the X-API-Key header or a bearer JWT
+func (a *authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(constants.APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+	authorization := r.Header.Get(constants.Authorization)
+	if authorization == "" {
+		return nil, fmt.Errorf("no credentials")
+	}
+	scheme, token, ok := cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, fmt.Errorf("unsupported authorization scheme")
+	}
+	claims, err := verifyJWT(strings.TrimSpace(token), a.jwks, a.config.Issuer, a.config.Audience, a.now())
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Subject: claims.Subject, Tenant: claims.Tenant, Method: MethodJWT, Scopes: claims.scopes()}, nil
+}
+
+func (a *authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	for _, apiKey := range a.config.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey.Key), []byte(key)) == 1 {
+			return &Principal{Subject: apiKey.Subject, Tenant: apiKey.Tenant, Method: MethodAPIKey, Scopes: apiKey.Scopes}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown api key")
+}
+
+func (a *authenticator) RequiredScopes(route string) []string {
+	return a.config.Scopes[route]
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/stretchr/testify/suite"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+type AuthTestSuite struct {
+	suite.Suite
+	rsaKey        *rsa.PrivateKey
+	authenticator Authenticator
+	router        *gin.Engine
+	principal     *Principal
+	loggerData    logging.ApiLoggerFields
+}
+
+func TestAuth(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+func (suite *AuthTestSuite) SetupSuite() {
+	var err error
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().Nil(err)
+}
+
+func (suite *AuthTestSuite) SetupTest() {
+	dir := suite.T().TempDir()
+	jwks := JWKS{Keys: []JWK{
+		{Kid: "hs", Kty: "oct", Alg: HS256, K: base64.RawURLEncoding.EncodeToString(hmacSecret)},
+		{
+			Kid: "rs",
+			Kty: "RSA",
+			Alg: RS256,
+			N:   base64.RawURLEncoding.EncodeToString(suite.rsaKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(suite.rsaKey.E)).Bytes()),
+		},
+	}}
+	config := Config{
+		APIKeys:  []APIKey{{Key: "secret-key", Subject: "batch-jobs", Scopes: []string{"track"}}},
+		JWKSFile: "jwks.json",
+		Issuer:   "https://issuer.example",
+		Scopes:   map[string][]string{"/track": {"track"}},
+	}
+	suite.writeJSON(filepath.Join(dir, "jwks.json"), jwks)
+	suite.writeJSON(filepath.Join(dir, "auth.json"), config)
+
+	loadedConfig, loadedJWKS, err := LoadConfig(filepath.Join(dir, "auth.json"))
+	suite.Require().Nil(err)
+	suite.authenticator = NewAuthenticator(loadedConfig, loadedJWKS)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()))
+	suite.router.POST("/track", AuthMiddleware(suite.authenticator, "/track"), func(c *gin.Context) {
+		suite.principal, _ = GetPrincipal(c)
+		suite.loggerData = logging.GetLogger(c).Data
+		c.Status(http.StatusOK)
+	})
+}
+
+func (suite *AuthTestSuite) writeJSON(path string, v interface{}) {
+	content, _ := json.Marshal(v)
+	suite.Require().Nil(ioutil.WriteFile(path, content, 0600))
+}
+
+func (suite *AuthTestSuite) token(alg, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, hmacSecret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case RS256:
+		digest := sha256.Sum256([]byte(signed))
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, suite.rsaKey, crypto.SHA256, digest[:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (suite *AuthTestSuite) claims(scope string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "passenger-desk",
+		"iss":   "https://issuer.example",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope,
+	}
+}
+
+func (suite *AuthTestSuite) track(header, value string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/track", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *AuthTestSuite) TestAPIKeyAuthenticatesPrincipal() {
+	recorder := suite.track(constants.APIKeyHeader, "secret-key")
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("batch-jobs", suite.principal.Subject)
+	suite.Equal(MethodAPIKey, suite.principal.Method)
+	suite.Equal("batch-jobs", suite.loggerData[constants.Principal])
+}
+
+func (suite *AuthTestSuite) TestHS256TokenAuthenticatesPrincipal() {
+	recorder := suite.track(constants.Authorization, "Bearer "+suite.token(HS256, "hs", suite.claims("track")))
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("passenger-desk", suite.principal.Subject)
+	suite.Equal(MethodJWT, suite.principal.Method)
+}
+
+func (suite *AuthTestSuite) TestRS256TokenAuthenticatesPrincipal() {
+	recorder := suite.track(constants.Authorization, "Bearer "+suite.token(RS256, "rs", suite.claims("read track")))
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal([]string{"read", "track"}, suite.principal.Scopes)
+}
+
+func (suite *AuthTestSuite) TestMissingCredentialsAreUnauthorized() {
+	recorder := suite.track("", "")
+
+	suite.Equal(http.StatusUnauthorized, recorder.Code)
+	suite.NotEmpty(recorder.Header().Get(constants.WWWAuthenticate))
+}
+
+func (suite *AuthTestSuite) TestUnknownAPIKeyIsUnauthorized() {
+	suite.Equal(http.StatusUnauthorized, suite.track(constants.APIKeyHeader, "guessed-key").Code)
+}
+
+func (suite *AuthTestSuite) TestExpiredTokenIsUnauthorized() {
+	claims := suite.claims("track")
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	suite.Equal(http.StatusUnauthorized, suite.track(constants.Authorization, "Bearer "+suite.token(HS256, "hs", claims)).Code)
+}
+
+func (suite *AuthTestSuite) TestTokenFromOtherIssuerIsUnauthorized() {
+	claims := suite.claims("track")
+	claims["iss"] = "https://other.example"
+
+	suite.Equal(http.StatusUnauthorized, suite.track(constants.Authorization, "Bearer "+suite.token(RS256, "rs", claims)).Code)
+}
+
+func (suite *AuthTestSuite) TestTamperedTokenIsUnauthorized() {
+	token := suite.token(HS256, "hs", suite.claims("track"))
+	tampered := token[:len(token)-4] + "AAAA"
+
+	suite.Equal(http.StatusUnauthorized, suite.track(constants.Authorization, "Bearer "+tampered).Code)
+}
+
+func (suite *AuthTestSuite) TestAlgorithmMustMatchKeyType() {
+	//an HS256 token must not be verified with an RSA key
+	suite.Equal(http.StatusUnauthorized, suite.track(constants.Authorization, "Bearer "+suite.token(HS256, "rs", suite.claims("track"))).Code)
+}
+
+func (suite *AuthTestSuite) TestMissingScopeIsForbidden() {
+	recorder := suite.track(constants.Authorization, "Bearer "+suite.token(HS256, "hs", suite.claims("read")))
+
+	suite.Equal(http.StatusForbidden, recorder.Code)
+}
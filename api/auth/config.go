@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+//Config is the content of the authentication config file
+type Config struct {
+	APIKeys []APIKey `json:"api_keys"`
+	//JWKSFile is the path of the JSON web key set used to verify JWTs, relative paths
+	//are resolved against the directory of the config file
+	JWKSFile string `json:"jwks_file"`
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+	//Scopes lists the scopes required on each route
+	Scopes map[string][]string `json:"scopes"`
+}
+
+//APIKey is a static key and the principal it authenticates
+type APIKey struct {
+	Key     string   `json:"key"`
+	Subject string   `json:"subject"`
+	Tenant  string   `json:"tenant"`
+	Scopes  []string `json:"scopes"`
+}
+
+//LoadConfig reads the authentication config and the JWKS file it refers to
+func LoadConfig(path string) (*Config, *JWKS, error) {
+	config := new(Config)
+	if err := readJSON(path, config); err != nil {
+		return nil, nil, err
+	}
+	for i, apiKey := range config.APIKeys {
+		if apiKey.Key == "" || apiKey.Subject == "" {
+			return nil, nil, fmt.Errorf("api key %d in %s needs a key and a subject", i, path)
+		}
+	}
+	if config.JWKSFile == "" {
+		return config, nil, nil
+	}
+
+	jwksFile := config.JWKSFile
+	if !filepath.IsAbs(jwksFile) {
+		jwksFile = filepath.Join(filepath.Dir(path), jwksFile)
+	}
+	jwks := new(JWKS)
+	if err := readJSON(jwksFile, jwks); err != nil {
+		return nil, nil, err
+	}
+	return config, jwks, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("invalid json in %s: %v", path, err)
+	}
+	return nil
+}
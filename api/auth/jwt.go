@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+//JWKS is a JSON web key set as described in RFC 7517
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+//JWK holds the members of a symmetric (oct) or RSA JSON web key
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scp       []string        `json:"scp"`
+	Tenant    string          `json:"tenant"`
+}
+
+//verifyJWT checks the signature and the registered claims of the token and returns its claims
+func verifyJWT(token string, jwks *JWKS, issuer, audience string, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWS compact serialization")
+	}
+
+	header := new(jwtHeader)
+	if err := decodeSegment(parts[0], header); err != nil {
+		return nil, fmt.Errorf("invalid header: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %v", err)
+	}
+	key, err := jwks.find(header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := key.verify(header.Alg, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	claims := new(jwtClaims)
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %v", err)
+	}
+	if claims.ExpiresAt == nil || now.Unix() >= *claims.ExpiresAt {
+		return nil, fmt.Errorf("token is expired")
+	}
+	if claims.NotBefore != nil && now.Unix() < *claims.NotBefore {
+		return nil, fmt.Errorf("token is not valid yet")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	if issuer != "" && claims.Issuer != issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if audience != "" && !claims.hasAudience(audience) {
+		return nil, fmt.Errorf("token is not issued for audience %q", audience)
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+//scopes returns the scopes from either the space separated scope claim or the scp array
+func (claims jwtClaims) scopes() []string {
+	if claims.Scope != "" {
+		return strings.Fields(claims.Scope)
+	}
+	return claims.Scp
+}
+
+//hasAudience handles aud as either a single string or an array of strings
+func (claims jwtClaims) hasAudience(audience string) bool {
+	var single string
+	if err := json.Unmarshal(claims.Audience, &single); err == nil {
+		return single == audience
+	}
+	var multiple []string
+	if err := json.Unmarshal(claims.Audience, &multiple); err == nil {
+		for _, aud := range multiple {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (jwks *JWKS) find(kid string, alg string) (*JWK, error) {
+	if jwks == nil {
+		return nil, fmt.Errorf("no JWKS configured")
+	}
+	for i, key := range jwks.Keys {
+		if key.Kid == kid && (key.Alg == "" || key.Alg == alg) {
+			return &jwks.Keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no key %q for algorithm %q", kid, alg)
+}
+
+func (key JWK) verify(alg string, signed []byte, signature []byte) error {
+	switch {
+	case alg == HS256 && key.Kty == "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(key.K)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", key.Kid, err)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case alg == RS256 && key.Kty == "RSA":
+		publicKey, err := key.rsaPublicKey()
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q is not supported for key %q", alg, key.Kid)
+}
+
+func (key JWK) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus of key %q: %v", key.Kid, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent of key %q: %v", key.Kid, err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//AuthMiddleware authenticates the caller of the route and checks the scopes required on it.
+//The principal is stored on the context and added to the request logger
+func AuthMiddleware(authenticator Authenticator, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.GetLogger(c).
+			WithField(constants.Interface, "AuthMiddleware").
+			WithField(constants.Method, route)
+
+		principal, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			logger.Warnf("Authenticate - %s", err.Error())
+			c.Header(constants.WWWAuthenticate, `Bearer realm="flight-paths-tracker"`)
+			errors.AbortWithErrorResponse(c, errors.ErrUnauthorized)
+			return
+		}
+
+		logger = logger.WithField(constants.Principal, principal.Subject)
+		if !principal.HasScopes(authenticator.RequiredScopes(route)) {
+			logger.Warnf("Missing scopes - %s", errors.ErrForbidden.Error())
+			errors.AbortWithErrorResponse(c, errors.ErrForbidden)
+			return
+		}
+
+		c.Set(constants.PRINCIPAL_KEY, principal)
+		c.Set(constants.LOGGER_KEY, logging.GetLogger(c).WithField(constants.Principal, principal.Subject))
+		c.Next()
+	}
+}
+
+//GetPrincipal returns the principal authenticated by AuthMiddleware, if any
+func GetPrincipal(c *gin.Context) (*Principal, bool) {
+	principal, ok := c.Get(constants.PRINCIPAL_KEY)
+	if !ok {
+		return nil, false
+	}
+	p, ok := principal.(*Principal)
+	return p, ok
+}
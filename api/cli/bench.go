@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/generator"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/service"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/client"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
+)
+
+//label of the requests which succeeded and of those which failed without an error response
+const (
+	benchOK             = "OK"
+	benchTransportError = "TRANSPORT_ERROR"
+)
+
+//benchResult is the outcome of one request
+type benchResult struct {
+	latency time.Duration
+	code    string
+}
+
+//Bench sends generated itineraries at a fixed concurrency to a server, or to the in-process service
+//when no target is given, and reports the throughput, latency percentiles and error codes
+func Bench(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("flight-paths-tracker bench", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	target := flagSet.String("target", "", "base URL of the server, such as http://localhost:8080, the in-process service is used when empty")
+	apiKey := flagSet.String("api-key", "", "API key sent to the target")
+	bearerToken := flagSet.String("bearer-token", "", "bearer token sent to the target")
+	requests := flagSet.Int("requests", 1000, "number of requests sent")
+	concurrency := flagSet.Int("concurrency", 10, "number of requests in flight at once")
+	tickets := flagSet.Int("tickets", 10, "number of tickets of each itinerary")
+	invalid := flagSet.Float64("invalid", 0.1, "fraction of the itineraries which are invalid or cannot be tracked")
+	seed := flagSet.Int64("seed", 0, "seed of the generated itineraries, 0 for a random seed")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: flight-paths-tracker bench [flags]")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if flagSet.NArg() > 0 || *requests < 1 || *concurrency < 1 || *tickets < 1 || *invalid < 0 || *invalid > 1 {
+		flagSet.Usage()
+		return ExitUsage
+	}
+	//the broken itineraries sent when --invalid is above 0 need 2 tickets
+	if *invalid > 0 && *tickets < 2 {
+		fmt.Fprintln(stderr, "--tickets must be at least 2 when --invalid is above 0")
+		flagSet.Usage()
+		return ExitUsage
+	}
+
+	send := benchInProcess()
+	if *target != "" {
+		cl, err := client.NewClient(client.Config{BaseURL: *target, APIKey: *apiKey, BearerToken: *bearerToken})
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not create the client: %v\n", err)
+			return ExitUsage
+		}
+		send = benchClient(cl)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	//itineraries are generated before the run so their cost is not measured
+	random := rand.New(rand.NewSource(*seed))
+	fixtureGenerator := generator.NewGenerator(*seed)
+	itineraries := make([][][]string, *requests)
+	for i := range itineraries {
+		var err error
+		if itineraries[i], err = benchItinerary(fixtureGenerator, random, *tickets, random.Float64() < *invalid); err != nil {
+			fmt.Fprintf(stderr, "Could not generate itineraries: %v\n", err)
+			return ExitUsage
+		}
+	}
+
+	results := make([]benchResult, *requests)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < *concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				requestStart := time.Now()
+				code := send(itineraries[i])
+				results[i] = benchResult{latency: time.Since(requestStart), code: code}
+			}
+		}()
+	}
+	for i := range itineraries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	printBenchReport(stdout, results, time.Since(start), *concurrency, *seed)
+	return ExitOK
+}
+
+//benchInProcess runs the validation and tracking of the API without a server
+func benchInProcess() func(tickets [][]string) string {
+	tracker := flightpath.NewTracker(nil)
+	return func(tickets [][]string) string {
+		_, e := track(context.Background(), tracker, tickets)
+		if e != nil {
+			return string(e.ErrorCode)
+		}
+		return benchOK
+	}
+}
+
+func benchClient(cl client.Client) func(tickets [][]string) string {
+	return func(tickets [][]string) string {
+		_, err := cl.Track(context.Background(), service.ToTickets(tickets))
+		var apiError *client.Error
+		switch {
+		case err == nil:
+			return benchOK
+		case stderrors.As(err, &apiError) && apiError.Code != "":
+			return string(apiError.Code)
+		case stderrors.As(err, &apiError):
+			return fmt.Sprintf("HTTP_%d", apiError.Status)
+		}
+		return benchTransportError
+	}
+}
+
+//benchBroken are the invalid itineraries sent, their tickets all have an origin and a destination
+var benchBroken = []struct {
+	kind   generator.Kind
+	defect generator.Defect
+}{
+	{kind: generator.RoundTrip},
+	{kind: generator.Disjoint},
+	{kind: generator.Broken, defect: generator.Lowercase},
+	{kind: generator.Broken, defect: generator.NonAlpha},
+	{kind: generator.Broken, defect: generator.WrongLength},
+	{kind: generator.Broken, defect: generator.SelfLoop},
+	{kind: generator.Broken, defect: generator.DuplicateTicket},
+	{kind: generator.Broken, defect: generator.MissingTicket},
+}
+
+//benchItinerary returns a linear itinerary, or one of the benchBroken ones
+func benchItinerary(fixtureGenerator generator.Generator, random *rand.Rand, length int, broken bool) ([][]string, error) {
+	if !broken {
+		fixture, err := fixtureGenerator.Generate(generator.Linear, length)
+		return fixture.Tickets, err
+	}
+	b := benchBroken[random.Intn(len(benchBroken))]
+	if b.kind == generator.Broken {
+		fixture, err := fixtureGenerator.Break(b.defect, length)
+		return fixture.Tickets, err
+	}
+	fixture, err := fixtureGenerator.Generate(b.kind, length)
+	return fixture.Tickets, err
+}
+
+func printBenchReport(stdout io.Writer, results []benchResult, elapsed time.Duration, concurrency int, seed int64) {
+	latencies := make([]time.Duration, len(results))
+	codes := make(map[string]int)
+	for i, result := range results {
+		latencies[i] = result.latency
+		codes[result.code]++
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	defer table.Flush()
+	fmt.Fprintf(table, "requests\t%d\n", len(results))
+	fmt.Fprintf(table, "concurrency\t%d\n", concurrency)
+	fmt.Fprintf(table, "seed\t%d\n", seed)
+	fmt.Fprintf(table, "duration\t%s\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(table, "throughput\t%.1f req/s\n", float64(len(results))/elapsed.Seconds())
+	for _, p := range []float64{50, 90, 99} {
+		fmt.Fprintf(table, "latency p%.0f\t%s\n", p, percentile(latencies, p))
+	}
+	fmt.Fprintf(table, "latency max\t%s\n", latencies[len(latencies)-1])
+
+	names := make([]string, 0, len(codes))
+	for code := range codes {
+		names = append(names, code)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(table, "\nCODE\tCOUNT\tSHARE")
+	for _, code := range names {
+		fmt.Fprintf(table, "%s\t%d\t%.1f%%\n", code, codes[code], 100*float64(codes[code])/float64(len(results)))
+	}
+}
+
+//percentile uses the nearest rank of the sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
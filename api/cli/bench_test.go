@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type BenchTestSuite struct {
+	suite.Suite
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func TestBench(t *testing.T) {
+	suite.Run(t, new(BenchTestSuite))
+}
+
+func (suite *BenchTestSuite) SetupTest() {
+	suite.stdout = new(bytes.Buffer)
+	suite.stderr = new(bytes.Buffer)
+}
+
+func (suite *BenchTestSuite) TestBenchInProcess() {
+	code := Bench([]string{"--requests", "200", "--concurrency", "4", "--tickets", "5", "--invalid", "0.5", "--seed", "7"}, nil, suite.stdout, suite.stderr)
+
+	suite.Equal(ExitOK, code, suite.stderr.String())
+	report := suite.stdout.String()
+	suite.Contains(report, "requests     200\n")
+	suite.Contains(report, "seed         7\n")
+	suite.Contains(report, "latency p99")
+	suite.Contains(report, benchOK)
+	suite.Contains(report, errors.InvalidTicket)
+	suite.Contains(report, errors.UnableToTrack)
+}
+
+func (suite *BenchTestSuite) TestBenchAgainstTarget() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_ = json.NewEncoder(w).Encode(errors.ErrRateLimited)
+	}))
+	defer server.Close()
+
+	code := Bench([]string{"--target", server.URL, "--requests", "10"}, nil, suite.stdout, suite.stderr)
+
+	suite.Equal(ExitOK, code, suite.stderr.String())
+	suite.Contains(suite.stdout.String(), errors.RateLimited+"  10     100.0%")
+}
+
+func (suite *BenchTestSuite) TestInvalidFlagsAreRejected() {
+	for _, args := range [][]string{
+		{"--requests", "0"},
+		{"--concurrency", "0"},
+		{"--tickets", "0"},
+		{"--tickets", "1", "--invalid", "1"},
+		{"--invalid", "2"},
+		{"extra"},
+	} {
+		suite.Equal(ExitUsage, Bench(args, nil, suite.stdout, suite.stderr), "%v", args)
+	}
+}
+
+func (suite *BenchTestSuite) TestSingleTicketNeedsValidItineraries() {
+	suite.Equal(ExitUsage, Bench([]string{"--tickets", "1"}, nil, suite.stdout, suite.stderr))
+	suite.Contains(suite.stderr.String(), "--tickets must be at least 2")
+
+	suite.stderr.Reset()
+	code := Bench([]string{"--tickets", "1", "--invalid", "0", "--requests", "10"}, nil, suite.stdout, suite.stderr)
+	suite.Equal(ExitOK, code, suite.stderr.String())
+}
+
+func (suite *BenchTestSuite) TestPercentile() {
+	latencies := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	suite.Equal(time.Duration(5), percentile(latencies, 50))
+	suite.Equal(time.Duration(9), percentile(latencies, 90))
+	suite.Equal(time.Duration(10), percentile(latencies, 99))
+	suite.Equal(time.Duration(1), percentile(latencies[:1], 50))
+}
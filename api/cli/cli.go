@@ -0,0 +1,48 @@
+//Package cli holds the subcommands of the flight-paths-tracker binary which run instead of the server
+package cli
+
+import (
+	"io"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+)
+
+//Command runs a subcommand with its arguments and returns the exit code of the process
+type Command func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+
+//Commands are the subcommands by name, flight-paths-tracker <name> [flags]
+var Commands = map[string]Command{
+	"track":    Track,
+	"replay":   Replay,
+	"bench":    Bench,
+	"generate": Generate,
+}
+
+//Exit codes of the subcommands, an error response exits with the code of its category
+const (
+	ExitOK            = 0
+	ExitInternal      = 1
+	ExitUsage         = 2
+	ExitInvalidTicket = 3
+	ExitUnableToTrack = 4
+	ExitTimeout       = 5
+)
+
+var exitCodes = map[errors.ErrorCode]int{
+	errors.BadRequest:    ExitUsage,
+	errors.InvalidTicket: ExitInvalidTicket,
+	errors.UnableToTrack: ExitUnableToTrack,
+	errors.Timeout:       ExitTimeout,
+	errors.Internal:      ExitInternal,
+}
+
+//ExitCode returns the exit code of an error response, codes without a category exit with ExitInternal
+func ExitCode(e *errors.ErrorResponse) int {
+	if e == nil {
+		return ExitOK
+	}
+	if code, ok := exitCodes[e.ErrorCode]; ok {
+		return code
+	}
+	return ExitInternal
+}
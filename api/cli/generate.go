@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/dto"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/generator"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/recording"
+)
+
+//Output formats of the generated itineraries
+const (
+	GenerateFixtures = "fixtures"
+	GenerateReplay   = "replay"
+)
+
+//Generate writes shuffled itineraries with their known answer as JSON lines, either as fixtures or
+//as a recording the replay command plays against a server
+func Generate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("flight-paths-tracker generate", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	count := flagSet.Int("count", 10, "number of itineraries generated")
+	tickets := flagSet.Int("tickets", 5, "number of tickets of each itinerary")
+	kinds := flagSet.String("kinds", joinKinds(generator.Kinds), "comma separated kinds of itineraries, generated in turn")
+	seed := flagSet.Int64("seed", 0, "seed of the itineraries, 0 for a random seed written to stderr")
+	format := flagSet.String("format", GenerateFixtures, "output format: fixtures or replay")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: flight-paths-tracker generate [flags]")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if flagSet.NArg() > 0 || *count < 1 || (*format != GenerateFixtures && *format != GenerateReplay) {
+		flagSet.Usage()
+		return ExitUsage
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+		fmt.Fprintf(stderr, "seed %d\n", *seed)
+	}
+	fixtureGenerator := generator.NewGenerator(*seed)
+	kindList := strings.Split(*kinds, ",")
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	recorder := recording.NewRecorder(stdout)
+	for i := 0; i < *count; i++ {
+		fixture, err := fixtureGenerator.Generate(generator.Kind(strings.TrimSpace(kindList[i%len(kindList)])), *tickets)
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not generate itineraries: %v\n", err)
+			return ExitUsage
+		}
+		if *format == GenerateFixtures {
+			err = encoder.Encode(fixture)
+		} else {
+			err = recordFixture(recorder, fixture)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not write itineraries: %v\n", err)
+			return ExitInternal
+		}
+	}
+	return ExitOK
+}
+
+//recordFixture writes the fixture as a /track request with the response the server gives
+func recordFixture(recorder recording.Recorder, fixture generator.Fixture) error {
+	request, err := json.Marshal(dto.Tickets{Tickets: fixture.Tickets})
+	if err != nil {
+		return err
+	}
+	response, err := json.Marshal(fixture.Answer())
+	if err != nil {
+		return err
+	}
+	status := http.StatusOK
+	headers := map[string]string{"Content-Type": "application/json; charset=utf-8"}
+	if fixture.Error != nil {
+		status = fixture.Error.HttpStatusCode
+		headers["Content-Language"] = errors.DefaultLanguage
+	}
+	return recorder.Record(recording.Exchange{
+		Request: recording.Request{
+			Method:  http.MethodPost,
+			Path:    "/track",
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    string(request),
+		},
+		Response: recording.Response{Status: status, Headers: headers, Body: string(response)},
+	})
+}
+
+func joinKinds(kinds []generator.Kind) string {
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = string(kind)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/generator"
+	"github.com/stretchr/testify/suite"
+)
+
+type GenerateTestSuite struct {
+	suite.Suite
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func TestGenerate(t *testing.T) {
+	suite.Run(t, new(GenerateTestSuite))
+}
+
+func (suite *GenerateTestSuite) SetupTest() {
+	suite.stdout = new(bytes.Buffer)
+	suite.stderr = new(bytes.Buffer)
+}
+
+func (suite *GenerateTestSuite) TestFixturesFollowTheKinds() {
+	code := Generate([]string{"--count", "4", "--tickets", "6", "--kinds", "linear, disjoint", "--seed", "3"}, nil, suite.stdout, suite.stderr)
+
+	suite.Equal(ExitOK, code, suite.stderr.String())
+	lines := strings.Split(strings.TrimSpace(suite.stdout.String()), "\n")
+	suite.Require().Len(lines, 4)
+	for i, line := range lines {
+		var fixture generator.Fixture
+		suite.Require().Nil(json.Unmarshal([]byte(line), &fixture))
+		suite.Equal([]generator.Kind{generator.Linear, generator.Disjoint}[i%2], fixture.Kind)
+		suite.Len(fixture.Tickets, 6)
+	}
+
+	//the same seed writes the same fixtures
+	output := suite.stdout.String()
+	suite.stdout.Reset()
+	Generate([]string{"--count", "4", "--tickets", "6", "--kinds", "linear,disjoint", "--seed", "3"}, nil, suite.stdout, suite.stderr)
+	suite.Equal(output, suite.stdout.String())
+}
+
+func (suite *GenerateTestSuite) TestReplayOutputMatchesTheServer() {
+	suite.Require().Equal(ExitOK, Generate([]string{"--count", "30", "--format", "replay", "--seed", "11"}, nil, suite.stdout, suite.stderr))
+
+	replayOutput := new(bytes.Buffer)
+	code := Replay(nil, strings.NewReader(suite.stdout.String()), replayOutput, suite.stderr)
+
+	suite.Equal(ExitOK, code, replayOutput.String())
+	suite.Equal("30 exchanges replayed, 0 differ\n", replayOutput.String())
+}
+
+func (suite *GenerateTestSuite) TestInvalidFlagsAreRejected() {
+	for _, args := range [][]string{
+		{"--count", "0"},
+		{"--format", "csv"},
+		{"--kinds", "spiral"},
+		{"--kinds", "repeated_airports", "--tickets", "2"},
+		{"extra"},
+	} {
+		suite.Equal(ExitUsage, Generate(args, nil, suite.stdout, suite.stderr), "%v", args)
+	}
+}
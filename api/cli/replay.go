@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/config"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/health"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/recording"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/router"
+)
+
+//ExitDifferences is the exit code of a replay which found responses differing from the recording
+const ExitDifferences = 6
+
+//Replay plays a recording against a running server, or an in-process one when no target is given,
+//and reports the responses whose status, headers or body differ from the recorded ones
+func Replay(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("flight-paths-tracker replay", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	target := flagSet.String("target", "", "base URL of the server, such as http://localhost:8080, an in-process server is used when empty")
+	configFile := flagSet.String("config", "", "config file of the in-process server, its authentication, rate limits and recording are turned off")
+	apiKey := flagSet.String("api-key", "", "API key sent to the target, credentials are not recorded")
+	bearerToken := flagSet.String("bearer-token", "", "bearer token sent to the target")
+	timeout := flagSet.Duration("timeout", 30*time.Second, "timeout of each request to the target")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: flight-paths-tracker replay [flags] [recording.jsonl]")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if flagSet.NArg() > 1 || (*target != "" && *configFile != "") {
+		flagSet.Usage()
+		return ExitUsage
+	}
+
+	exchanges, err := readRecording(flagSet.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "Could not read recording: %v\n", err)
+		return ExitUsage
+	}
+
+	var send func(req *http.Request) (*http.Response, error)
+	if *target != "" {
+		httpClient := &http.Client{Timeout: *timeout}
+		send = httpClient.Do
+	} else {
+		handler, err := inProcessServer(*configFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not start the in-process server: %v\n", err)
+			return ExitUsage
+		}
+		send = func(req *http.Request) (*http.Response, error) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder.Result(), nil
+		}
+	}
+
+	differences := 0
+	for i, exchange := range exchanges {
+		req, err := http.NewRequest(exchange.Request.Method, strings.TrimRight(*target, "/")+exchange.Request.Path, strings.NewReader(exchange.Request.Body))
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not replay exchange %d: %v\n", i+1, err)
+			return ExitUsage
+		}
+		for name, value := range exchange.Request.Headers {
+			req.Header.Set(name, value)
+		}
+		if *apiKey != "" {
+			req.Header.Set(constants.APIKeyHeader, *apiKey)
+		}
+		if *bearerToken != "" {
+			req.Header.Set(constants.Authorization, "Bearer "+*bearerToken)
+		}
+
+		replayed, err := replay(send, req)
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not replay exchange %d: %v\n", i+1, err)
+			return ExitInternal
+		}
+		if sameResponse(exchange.Response, replayed) {
+			continue
+		}
+		differences++
+		fmt.Fprintf(stdout, "exchange %d: %s %s\n", i+1, exchange.Request.Method, exchange.Request.Path)
+		if exchange.Response.Status != replayed.Status {
+			fmt.Fprintf(stdout, "  status:   recorded %d, replayed %d\n", exchange.Response.Status, replayed.Status)
+		}
+		for _, name := range recording.ResponseHeaders {
+			if recorded, ok := exchange.Response.Headers[name]; ok && recorded != replayed.Headers[name] {
+				fmt.Fprintf(stdout, "  %s: recorded %q, replayed %q\n", name, recorded, replayed.Headers[name])
+			}
+		}
+		fmt.Fprintf(stdout, "  recorded: %s\n  replayed: %s\n", strings.TrimSpace(exchange.Response.Body), strings.TrimSpace(replayed.Body))
+	}
+
+	fmt.Fprintf(stdout, "%d exchanges replayed, %d differ\n", len(exchanges), differences)
+	if differences > 0 {
+		return ExitDifferences
+	}
+	return ExitOK
+}
+
+func readRecording(path string, stdin io.Reader) ([]recording.Exchange, error) {
+	if path == "" || path == "-" {
+		return recording.ReadExchanges(stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return recording.ReadExchanges(file)
+}
+
+//inProcessServer serves the routes of the server without authentication, rate limits nor recording,
+//so every recorded request reaches the tracking logic. Its logs are turned off
+func inProcessServer(configFile string) (http.Handler, error) {
+	cfg := config.Default()
+	if configFile != "" {
+		var err error
+		if cfg, err = config.Load([]string{"--config", configFile}, func(string) string { return "" }, ioutil.Discard); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Auth.ConfigFile = ""
+	cfg.Record.File = ""
+	cfg.RateLimits = map[string]config.RateLimitConfig{"/track": {Rate: math.MaxInt32, Burst: math.MaxInt32}}
+
+	if err := logging.NewLoggerEntry().SetLevel(logging.PANIC); err != nil {
+		return nil, err
+	}
+	gin.SetMode(gin.ReleaseMode)
+	handler, _ := router.SetupRouter(cfg, health.NewHealth())
+	return handler, nil
+}
+
+func replay(send func(req *http.Request) (*http.Response, error), req *http.Request) (recording.Response, error) {
+	resp, err := send(req)
+	if err != nil {
+		return recording.Response{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return recording.Response{}, err
+	}
+	headers := make(map[string]string)
+	for _, name := range recording.ResponseHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	return recording.Response{Status: resp.StatusCode, Headers: headers, Body: string(body)}, nil
+}
+
+//sameResponse compares the status, the recorded headers and the bodies. JSON bodies are compared by value
+//without the problem+json instance, which is the ID of the request and so differs on every request
+func sameResponse(recorded, replayed recording.Response) bool {
+	if recorded.Status != replayed.Status {
+		return false
+	}
+	for name, value := range recorded.Headers {
+		if replayed.Headers[name] != value {
+			return false
+		}
+	}
+	var recordedBody, replayedBody interface{}
+	if json.Unmarshal([]byte(recorded.Body), &recordedBody) == nil && json.Unmarshal([]byte(replayed.Body), &replayedBody) == nil {
+		return reflect.DeepEqual(withoutInstance(recordedBody), withoutInstance(replayedBody))
+	}
+	return bytes.Equal(bytes.TrimSpace([]byte(recorded.Body)), bytes.TrimSpace([]byte(replayed.Body)))
+}
+
+func withoutInstance(body interface{}) interface{} {
+	if problem, ok := body.(map[string]interface{}); ok {
+		delete(problem, "instance")
+	}
+	return body
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/recording"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplayTestSuite struct {
+	suite.Suite
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func TestReplay(t *testing.T) {
+	suite.Run(t, new(ReplayTestSuite))
+}
+
+func (suite *ReplayTestSuite) SetupTest() {
+	suite.stdout = new(bytes.Buffer)
+	suite.stderr = new(bytes.Buffer)
+}
+
+func (suite *ReplayTestSuite) recordingOf(exchanges ...recording.Exchange) string {
+	output := new(bytes.Buffer)
+	recorder := recording.NewRecorder(output)
+	for _, exchange := range exchanges {
+		suite.Require().Nil(recorder.Record(exchange))
+	}
+	return output.String()
+}
+
+func trackExchange(body string, status int, response string) recording.Exchange {
+	return recording.Exchange{
+		Request:  recording.Request{Method: http.MethodPost, Path: "/track", Headers: map[string]string{"Content-Type": "application/json"}, Body: body},
+		Response: recording.Response{Status: status, Body: response},
+	}
+}
+
+func (suite *ReplayTestSuite) TestReplayInProcessMatches() {
+	input := suite.recordingOf(
+		trackExchange(`{"tickets": [["IND", "EWR"], ["SFO", "IND"]]}`, http.StatusOK, `["SFO","EWR"]`),
+		trackExchange(`{"tickets": [["IND", "EWR"], ["EWR", "IND"]]}`, http.StatusUnprocessableEntity,
+			`{"status": 422, "error_code": "ERR_API_UNABLE_TO_TRACK", "error_message": "Unable to track source and destination for the given tickets"}`),
+	)
+
+	code := Replay(nil, strings.NewReader(input), suite.stdout, suite.stderr)
+
+	suite.Equal(ExitOK, code, suite.stdout.String()+suite.stderr.String())
+	suite.Equal("2 exchanges replayed, 0 differ\n", suite.stdout.String())
+}
+
+func (suite *ReplayTestSuite) TestReplayIgnoresProblemInstance() {
+	exchange := trackExchange(`{"tickets": [["IND", "EWR"], ["EWR", "IND"]]}`, http.StatusUnprocessableEntity,
+		`{"type": "https://github.com/kumareswaramoorthi/flight-paths-tracker#ERR_API_UNABLE_TO_TRACK", "title": "Unprocessable Entity", "status": 422,
+		"detail": "Unable to track source and destination for the given tickets", "instance": "recorded-request-id", "error_code": "ERR_API_UNABLE_TO_TRACK"}`)
+	exchange.Request.Headers["Accept"] = "application/problem+json"
+
+	code := Replay(nil, strings.NewReader(suite.recordingOf(exchange)), suite.stdout, suite.stderr)
+
+	suite.Equal(ExitOK, code, suite.stdout.String()+suite.stderr.String())
+	suite.Equal("1 exchanges replayed, 0 differ\n", suite.stdout.String())
+}
+
+func (suite *ReplayTestSuite) TestReplayReportsDifferences() {
+	input := suite.recordingOf(
+		trackExchange(`{"tickets": [["IND", "EWR"], ["SFO", "IND"]]}`, http.StatusOK, `["SFO","EWR"]`),
+		trackExchange(`{"tickets": [["IND", "EWR"]]}`, http.StatusOK, `["SFO","EWR"]`),
+	)
+
+	code := Replay(nil, strings.NewReader(input), suite.stdout, suite.stderr)
+
+	suite.Equal(ExitDifferences, code)
+	suite.Contains(suite.stdout.String(), "exchange 2: POST /track\n")
+	suite.Contains(suite.stdout.String(), `replayed: ["IND","EWR"]`)
+	suite.Contains(suite.stdout.String(), "2 exchanges replayed, 1 differ\n")
+}
+
+func (suite *ReplayTestSuite) TestReplayAgainstTarget() {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	input := suite.recordingOf(trackExchange(`{"tickets": [["IND", "EWR"]]}`, http.StatusOK, `["IND","EWR"]`))
+
+	code := Replay([]string{"--target", server.URL, "--api-key", "key"}, strings.NewReader(input), suite.stdout, suite.stderr)
+
+	suite.Equal(ExitDifferences, code)
+	suite.Contains(suite.stdout.String(), "status:   recorded 200, replayed 429")
+	suite.Require().Len(requests, 1)
+	suite.Equal("/track", requests[0].URL.Path)
+	suite.Equal("key", requests[0].Header.Get(constants.APIKeyHeader))
+	suite.Equal("application/json", requests[0].Header.Get("Content-Type"))
+}
+
+func (suite *ReplayTestSuite) TestReplayComparesRecordedHeaders() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(`["IND","EWR"]`))
+	}))
+	defer server.Close()
+	exchange := trackExchange(`{"tickets": [["IND", "EWR"]]}`, http.StatusOK, `["IND","EWR"]`)
+	exchange.Response.Headers = map[string]string{"Content-Type": "application/json; charset=utf-8"}
+
+	code := Replay([]string{"--target", server.URL}, strings.NewReader(suite.recordingOf(exchange)), suite.stdout, suite.stderr)
+
+	suite.Equal(ExitDifferences, code)
+	suite.Contains(suite.stdout.String(), `Content-Type: recorded "application/json; charset=utf-8", replayed "text/plain"`)
+}
+
+func (suite *ReplayTestSuite) TestInvalidRecordingIsRejected() {
+	suite.Equal(ExitUsage, Replay(nil, strings.NewReader("not json"), suite.stdout, suite.stderr))
+	suite.Contains(suite.stderr.String(), "Could not read recording")
+}
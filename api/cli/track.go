@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/airports"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/dto"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/service"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
+)
+
+//Input formats of the tickets
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+//Output formats of the result
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+//Track validates and tracks the tickets of a file, or stdin when no file or - is given, without a server.
+//It prints the source and destination, or the error response, and exits with the code of the error
+func Track(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("flight-paths-tracker track", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	format := flagSet.String("format", "", "input format: json, ndjson or csv, by default taken from the file extension or json")
+	output := flagSet.String("output", OutputTable, "output format: table or json")
+	airportsFile := flagSet.String("airports-file", "", "file of the known airport codes, any well formed code is accepted when empty")
+	timeout := flagSet.Duration("timeout", 10*time.Second, "time allowed to track the tickets, 0 for no limit")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: flight-paths-tracker track [flags] [file]")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if flagSet.NArg() > 1 || (*output != OutputTable && *output != OutputJSON) {
+		flagSet.Usage()
+		return ExitUsage
+	}
+
+	tickets, err := readTickets(flagSet.Arg(0), *format, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "Could not read tickets: %v\n", err)
+		return printResult(stdout, *output, nil, errors.ErrBadRequest)
+	}
+
+	registry := airports.NewRegistry()
+	if *airportsFile != "" {
+		if registry, err = airports.LoadRegistry(*airportsFile); err != nil {
+			fmt.Fprintf(stderr, "Could not load airports: %v\n", err)
+			return ExitUsage
+		}
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	itinerary, e := track(ctx, flightpath.NewTracker(registry), tickets)
+	return printResult(stdout, *output, itinerary, e)
+}
+
+//track runs the validation and tracking of the API on the tickets
+func track(ctx context.Context, tracker flightpath.Tracker, tickets [][]string) ([]string, *errors.ErrorResponse) {
+	validationErrors, err := service.Validate(ctx, tracker, tickets)
+	if err != nil {
+		return nil, service.ErrorResponse(err)
+	}
+	if len(validationErrors) > 0 {
+		return nil, errors.ErrInvalidTicket.WithErrors(validationErrors)
+	}
+	itinerary, err := tracker.Track(ctx, service.ToTickets(tickets))
+	if err != nil {
+		return nil, service.ErrorResponse(err)
+	}
+	return []string{itinerary.Source, itinerary.Destination}, nil
+}
+
+func readTickets(path, format string, stdin io.Reader) ([][]string, error) {
+	input := stdin
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+	if format == "" {
+		format = formatOf(path)
+	}
+
+	switch format {
+	case FormatJSON:
+		return readJSON(input)
+	case FormatNDJSON:
+		return readNDJSON(input)
+	case FormatCSV:
+		return readCSV(input)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+//formatOf takes the format from the file extension, stdin and other files are read as JSON
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".csv":
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+//readJSON accepts the request body of the API, {"tickets": [["SFO", "EWR"]]}, or the bare list of tickets
+func readJSON(input io.Reader) ([][]string, error) {
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("[")) {
+		var tickets [][]string
+		return tickets, json.Unmarshal(content, &tickets)
+	}
+	body := new(dto.Tickets)
+	if err := json.Unmarshal(content, body); err != nil {
+		return nil, err
+	}
+	return body.Tickets, nil
+}
+
+//readNDJSON reads one ticket per line, ["SFO", "EWR"], blank lines are skipped
+func readNDJSON(input io.Reader) ([][]string, error) {
+	var tickets [][]string
+	scanner := bufio.NewScanner(input)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var ticket []string
+		if err := json.Unmarshal(text, &ticket); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, scanner.Err()
+}
+
+//readCSV reads one ticket per record, origin then destination, an origin,destination header is skipped
+func readCSV(input io.Reader) ([][]string, error) {
+	reader := csv.NewReader(input)
+	//records of another size are reported as tickets of the wrong size
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && len(records[0]) == 2 &&
+		strings.EqualFold(records[0][0], flightpath.FieldOrigin) && strings.EqualFold(records[0][1], flightpath.FieldDestination) {
+		records = records[1:]
+	}
+	return records, nil
+}
+
+func printResult(stdout io.Writer, output string, srcdst []string, e *errors.ErrorResponse) int {
+	if output == OutputJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if e != nil {
+			_ = encoder.Encode(e)
+		} else {
+			_ = encoder.Encode(srcdst)
+		}
+		return ExitCode(e)
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	defer table.Flush()
+	if e == nil {
+		fmt.Fprintln(table, "SOURCE\tDESTINATION")
+		fmt.Fprintf(table, "%s\t%s\n", srcdst[0], srcdst[1])
+		return ExitOK
+	}
+	fmt.Fprintf(table, "%s\t%s\n", e.ErrorCode, e.ErrorMessage)
+	if len(e.Errors) > 0 {
+		fmt.Fprintln(table, "\nTICKET\tFIELD\tVALUE\tREASON")
+		for _, validationError := range e.Errors {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%s\n", validationError.Ticket, validationError.Field, validationError.Value, validationError.Reason)
+		}
+	}
+	return ExitCode(e)
+}
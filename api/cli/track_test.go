@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type TrackTestSuite struct {
+	suite.Suite
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func TestTrack(t *testing.T) {
+	suite.Run(t, new(TrackTestSuite))
+}
+
+func (suite *TrackTestSuite) SetupTest() {
+	suite.stdout = new(bytes.Buffer)
+	suite.stderr = new(bytes.Buffer)
+}
+
+func (suite *TrackTestSuite) track(stdin string, args ...string) int {
+	return Track(args, strings.NewReader(stdin), suite.stdout, suite.stderr)
+}
+
+func (suite *TrackTestSuite) writeFile(name, content string) string {
+	path := filepath.Join(suite.T().TempDir(), name)
+	suite.Require().Nil(ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func (suite *TrackTestSuite) TestTracksRequestBodyFromStdin() {
+	code := suite.track(`{"tickets": [["IND", "EWR"], ["SFO", "ATL"], ["GSO", "IND"], ["ATL", "GSO"]]}`)
+
+	suite.Equal(ExitOK, code)
+	suite.Equal("SOURCE  DESTINATION\nSFO     EWR\n", suite.stdout.String())
+}
+
+func (suite *TrackTestSuite) TestTracksEveryFormat() {
+	for _, path := range []string{
+		suite.writeFile("tickets.json", `[["IND", "EWR"], ["SFO", "IND"]]`),
+		suite.writeFile("tickets.ndjson", "[\"IND\", \"EWR\"]\n\n[\"SFO\", \"IND\"]\n"),
+		suite.writeFile("tickets.csv", "origin,destination\nIND, EWR\nSFO,IND\n"),
+	} {
+		suite.stdout.Reset()
+		code := suite.track("", "--output", "json", path)
+
+		suite.Equal(ExitOK, code, path)
+		var srcdst []string
+		suite.Nil(json.Unmarshal(suite.stdout.Bytes(), &srcdst), path)
+		suite.Equal([]string{"SFO", "EWR"}, srcdst, path)
+	}
+}
+
+func (suite *TrackTestSuite) TestInvalidTicketsAreListed() {
+	code := suite.track("IND,EWR\nsfo,IND,ATL\nsfo,IND\n", "--format", "csv", "--output", "json", "-")
+
+	suite.Equal(ExitInvalidTicket, code)
+	var response errors.ErrorResponse
+	suite.Require().Nil(json.Unmarshal(suite.stdout.Bytes(), &response))
+	suite.Equal(errors.ErrorCode(errors.InvalidTicket), response.ErrorCode)
+	suite.Equal([]errors.ValidationError{
+		{Ticket: 1, Reason: errors.ReasonWrongTicketSize},
+		{Ticket: 2, Field: errors.FieldOrigin, Value: "sfo", Reason: errors.ReasonLowercase},
+	}, response.Errors)
+}
+
+func (suite *TrackTestSuite) TestExitCodesFollowTheErrorCode() {
+	suite.Equal(ExitUnableToTrack, suite.track(`[["IND", "EWR"], ["EWR", "IND"]]`))
+	suite.Contains(suite.stdout.String(), errors.UnableToTrack)
+
+	suite.Equal(ExitUsage, suite.track(`{"tickets": `))
+	suite.Contains(suite.stderr.String(), "Could not read tickets")
+
+	suite.Equal(ExitUsage, suite.track("", "--output", "yaml"))
+	suite.Equal(ExitInternal, ExitCode(errors.ErrRateLimited))
+}
+
+func (suite *TrackTestSuite) TestUnknownAirportsAreRejected() {
+	airportsFile := suite.writeFile("airports.txt", "IND\nEWR\n")
+
+	code := suite.track(`[["IND", "EWR"], ["SFO", "IND"]]`, "--airports-file", airportsFile)
+
+	suite.Equal(ExitInvalidTicket, code)
+	suite.Contains(suite.stdout.String(), errors.ReasonUnknownAirport)
+}
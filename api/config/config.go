@@ -0,0 +1,413 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tracing"
+	"gopkg.in/yaml.v2"
+)
+
+//Config holds every setting of the server. Settings are loaded from the defaults, then the
+//config file, then the environment variables and last the command line flags
+type Config struct {
+	Server     ServerConfig               `yaml:"server"`
+	TLS        TLSConfig                  `yaml:"tls"`
+	Log        LogConfig                  `yaml:"log"`
+	AccessLog  AccessLogConfig            `yaml:"access_log"`
+	Record     RecordConfig               `yaml:"record"`
+	Admin      AdminConfig                `yaml:"admin"`
+	Auth       AuthConfig                 `yaml:"auth"`
+	Airports   AirportsConfig             `yaml:"airports"`
+	Tenants    TenantsConfig              `yaml:"tenants"`
+	Tracing    TracingConfig              `yaml:"tracing"`
+	RateLimits map[string]RateLimitConfig `yaml:"rate_limits"`
+	Timeouts   map[string]Duration        `yaml:"timeouts"`
+
+	//PrintConfig asks for the effective config to be printed instead of starting the server
+	PrintConfig bool `yaml:"-"`
+}
+
+//ServerConfig is the listener of the server. Client IPs are taken from the X-Forwarded-For header only
+//for requests coming from one of the trusted proxies, given as IP addresses or CIDR ranges
+type ServerConfig struct {
+	Addr            string   `yaml:"addr"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"`
+	DrainDelay      Duration `yaml:"drain_delay"`
+	GinMode         string   `yaml:"gin_mode"`
+	TrustedProxies  []string `yaml:"trusted_proxies"`
+}
+
+//TLSConfig turns on HTTPS when a certificate is given, and mutual TLS when a client CA bundle is given too
+type TLSConfig struct {
+	CertFile       string   `yaml:"cert_file"`
+	KeyFile        string   `yaml:"key_file"`
+	ClientCAFile   string   `yaml:"client_ca_file"`
+	ReloadInterval Duration `yaml:"reload_interval"`
+}
+
+//Enabled reports whether the server is served over TLS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+type LogConfig struct {
+	Level     string             `yaml:"level"`
+	Format    string             `yaml:"format"`
+	Output    LogOutputConfig    `yaml:"output"`
+	Redaction LogRedactionConfig `yaml:"redaction"`
+}
+
+//LogRedactionConfig hides personal data from logs and spans, the HMAC key of the hash mode
+//is read from a file so it never shows in the config
+type LogRedactionConfig struct {
+	Fields      []string `yaml:"fields"`
+	Patterns    []string `yaml:"patterns"`
+	Mode        string   `yaml:"mode"`
+	HMACKeyFile string   `yaml:"hmac_key_file"`
+}
+
+//LogOutputConfig is where log entries are written: stdout, a rotated file or the local syslog
+type LogOutputConfig struct {
+	Sink       string `yaml:"sink"`
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxAgeDays int    `yaml:"max_age_days"`
+	MaxBackups int    `yaml:"max_backups"`
+	SyslogTag  string `yaml:"syslog_tag"`
+}
+
+//AccessLogConfig selects the request headers written to the access log and the values redacted from it
+type AccessLogConfig struct {
+	Headers       []string `yaml:"headers"`
+	RedactHeaders []string `yaml:"redact_headers"`
+	RedactQuery   []string `yaml:"redact_query"`
+}
+
+//RecordConfig appends the requests and responses of the tracking routes to a file for the replay command
+type RecordConfig struct {
+	File    string   `yaml:"file"`
+	Headers []string `yaml:"headers"`
+}
+
+//AdminConfig serves the admin API on its own address when one is given
+type AdminConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+type AuthConfig struct {
+	ConfigFile string `yaml:"config_file"`
+}
+
+//AirportsConfig restricts airport codes to those of a file, tenants listed in tenant_files use a file of their own
+type AirportsConfig struct {
+	File        string               `yaml:"file"`
+	TenantFiles map[tenant.ID]string `yaml:"tenant_files"`
+}
+
+//TenantsConfig lists the tenants a request may name in its X-Tenant-ID header without
+//authenticating, the tenant of an authenticated principal is always used
+type TenantsConfig struct {
+	Allowed []string `yaml:"allowed"`
+}
+
+type TracingConfig struct {
+	Exporter   string  `yaml:"exporter"`
+	File       string  `yaml:"file"`
+	SampleRate float64 `yaml:"sample_rate"`
+}
+
+type RateLimitConfig struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+//Default returns the settings used when nothing else is configured
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ShutdownTimeout: Duration(20 * time.Second),
+			DrainDelay:      Duration(5 * time.Second),
+			GinMode:         gin.DebugMode,
+		},
+		TLS: TLSConfig{
+			ReloadInterval: Duration(time.Minute),
+		},
+		Log: LogConfig{
+			Level:  logging.INFO,
+			Format: constants.TEXT,
+			Output: LogOutputConfig{
+				Sink:       logging.SinkStdout,
+				MaxSizeMB:  100,
+				MaxAgeDays: 7,
+				MaxBackups: 5,
+				SyslogTag:  "flight-paths-tracker",
+			},
+			Redaction: LogRedactionConfig{
+				Fields: []string{"passenger_name", "passenger_id", "pnr"},
+				Mode:   logging.RedactMask,
+			},
+		},
+		AccessLog: AccessLogConfig{
+			Headers:       []string{"User-Agent"},
+			RedactHeaders: []string{constants.Authorization, constants.APIKeyHeader, "Cookie"},
+			RedactQuery:   []string{"api_key", "token"},
+		},
+		Record: RecordConfig{
+			Headers: []string{"Accept", "Accept-Language", "Content-Type", constants.TenantHeader},
+		},
+		Tracing: TracingConfig{
+			Exporter:   tracing.ExporterNone,
+			SampleRate: 1,
+		},
+		RateLimits: map[string]RateLimitConfig{
+			"/track": {Rate: 10, Burst: 20},
+		},
+		Timeouts: map[string]Duration{
+			"/track": Duration(10 * time.Second),
+		},
+	}
+}
+
+//Load builds the config from the command line arguments and the environment, the config file
+//is named by the --config flag or the CONFIG_FILE environment variable
+func Load(args []string, getenv func(string) string, output io.Writer) (*Config, error) {
+	flagSet := flag.NewFlagSet("flight-paths-tracker", flag.ContinueOnError)
+	flagSet.SetOutput(output)
+	configFile := flagSet.String("config", "", "path of the YAML config file (env "+EnvConfigFile+")")
+	printConfig := flagSet.Bool("print-config", false, "print the effective config and exit")
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		flagSet.Var(recorder{name: s.flag, values: flagValues}, s.flag, s.usage+" (env "+s.env+")")
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+	if flagSet.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", flagSet.Args())
+	}
+
+	cfg := Default()
+	if *configFile == "" {
+		*configFile = getenv(EnvConfigFile)
+	}
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.value(cfg).Set(value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagValues[s.flag]; ok {
+			if err := s.value(cfg).Set(value); err != nil {
+				return nil, fmt.Errorf("invalid --%s: %v", s.flag, err)
+			}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.PrintConfig = *printConfig
+	return cfg, nil
+}
+
+//loadFile reads the settings of a YAML file over the defaults, unknown keys are rejected.
+//Rate limits and timeouts given in the file replace the default ones as a whole
+func (cfg *Config) loadFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	defaultRateLimits, defaultTimeouts := cfg.RateLimits, cfg.Timeouts
+	cfg.RateLimits, cfg.Timeouts = nil, nil
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if cfg.RateLimits == nil {
+		cfg.RateLimits = defaultRateLimits
+	}
+	if cfg.Timeouts == nil {
+		cfg.Timeouts = defaultTimeouts
+	}
+	return nil
+}
+
+//Validate reports the first invalid setting
+func (cfg *Config) Validate() error {
+	if _, _, err := net.SplitHostPort(cfg.Server.Addr); err != nil {
+		return fmt.Errorf("server.addr: %v", err)
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("server.shutdown_timeout must be positive")
+	}
+	if cfg.Server.DrainDelay < 0 {
+		return fmt.Errorf("server.drain_delay must not be negative")
+	}
+	if !oneOf(cfg.Server.GinMode, gin.DebugMode, gin.ReleaseMode, gin.TestMode) {
+		return fmt.Errorf("server.gin_mode must be one of %s, %s or %s", gin.DebugMode, gin.ReleaseMode, gin.TestMode)
+	}
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("server.trusted_proxies: %q is neither an IP address nor a CIDR range", proxy)
+			}
+		}
+	}
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return fmt.Errorf("tls.cert_file and tls.key_file must be given together")
+	}
+	if cfg.TLS.ClientCAFile != "" && !cfg.TLS.Enabled() {
+		return fmt.Errorf("tls.client_ca_file needs tls.cert_file and tls.key_file")
+	}
+	if cfg.TLS.ReloadInterval < 0 {
+		return fmt.Errorf("tls.reload_interval must not be negative")
+	}
+	if !oneOf(cfg.Log.Level, logging.PANIC, logging.FATAL, logging.ERROR, logging.WARN, logging.WARNING, logging.INFO, logging.PRINT, logging.DEBUG, logging.TRACE) {
+		return fmt.Errorf("log.level %q is not a valid level", cfg.Log.Level)
+	}
+	if err := logging.ValidateFormat(cfg.Log.Format); err != nil {
+		return fmt.Errorf("log.format: %v", err)
+	}
+	if !oneOf(cfg.Log.Redaction.Mode, logging.RedactMask, logging.RedactHash) {
+		return fmt.Errorf("log.redaction.mode must be %s or %s", logging.RedactMask, logging.RedactHash)
+	}
+	if cfg.Log.Redaction.Mode == logging.RedactHash && cfg.Log.Redaction.HMACKeyFile == "" {
+		return fmt.Errorf("log.redaction.hmac_key_file is required by the %s mode", logging.RedactHash)
+	}
+	for _, pattern := range cfg.Log.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("log.redaction.patterns: %v", err)
+		}
+	}
+	if !oneOf(cfg.Log.Output.Sink, logging.SinkStdout, logging.SinkFile, logging.SinkSyslog) {
+		return fmt.Errorf("log.output.sink must be one of %s, %s or %s", logging.SinkStdout, logging.SinkFile, logging.SinkSyslog)
+	}
+	if cfg.Log.Output.Sink == logging.SinkFile && cfg.Log.Output.File == "" {
+		return fmt.Errorf("log.output.file is required by the %s sink", logging.SinkFile)
+	}
+	if cfg.Log.Output.MaxSizeMB < 1 || cfg.Log.Output.MaxAgeDays < 0 || cfg.Log.Output.MaxBackups < 0 {
+		return fmt.Errorf("log.output needs a max_size_mb of at least 1 and no negative max_age_days or max_backups")
+	}
+	if cfg.Admin.Addr != "" {
+		if _, _, err := net.SplitHostPort(cfg.Admin.Addr); err != nil {
+			return fmt.Errorf("admin.addr: %v", err)
+		}
+		if cfg.Admin.Addr == cfg.Server.Addr {
+			return fmt.Errorf("admin.addr must differ from server.addr")
+		}
+		if cfg.Auth.ConfigFile == "" {
+			return fmt.Errorf("admin.addr needs auth.config_file, the admin API is always authenticated")
+		}
+	}
+	for id, file := range cfg.Airports.TenantFiles {
+		if !tenant.Valid(string(id)) {
+			return fmt.Errorf("airports.tenant_files: %q is not a valid tenant ID", id)
+		}
+		if file == "" {
+			return fmt.Errorf("airports.tenant_files.%s needs a file", id)
+		}
+	}
+	for _, id := range cfg.Tenants.Allowed {
+		if !tenant.Valid(id) {
+			return fmt.Errorf("tenants.allowed: %q is not a valid tenant ID", id)
+		}
+	}
+	if !oneOf(cfg.Tracing.Exporter, tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterFile) {
+		return fmt.Errorf("tracing.exporter must be one of %s, %s or %s", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterFile)
+	}
+	if cfg.Tracing.Exporter == tracing.ExporterFile && cfg.Tracing.File == "" {
+		return fmt.Errorf("tracing.file is required by the %s exporter", tracing.ExporterFile)
+	}
+	if cfg.Tracing.SampleRate < 0 || cfg.Tracing.SampleRate > 1 {
+		return fmt.Errorf("tracing.sample_rate must be between 0 and 1")
+	}
+	for route, limit := range cfg.RateLimits {
+		if limit.Rate <= 0 || limit.Burst < 1 {
+			return fmt.Errorf("rate_limits.%s needs a positive rate and a burst of at least 1", route)
+		}
+	}
+	for route, timeout := range cfg.Timeouts {
+		if timeout < 0 {
+			return fmt.Errorf("timeouts.%s must not be negative", route)
+		}
+	}
+	return nil
+}
+
+//RestartRequired lists the settings which differ between the configs but are only read at start up
+func RestartRequired(current, next *Config) []string {
+	var settings []string
+	if current.Server.Addr != next.Server.Addr {
+		settings = append(settings, "server.addr")
+	}
+	if current.Server.GinMode != next.Server.GinMode {
+		settings = append(settings, "server.gin_mode")
+	}
+	if !reflect.DeepEqual(current.Server.TrustedProxies, next.Server.TrustedProxies) {
+		settings = append(settings, "server.trusted_proxies")
+	}
+	if current.TLS != next.TLS {
+		settings = append(settings, "tls")
+	}
+	if current.Log.Output != next.Log.Output {
+		settings = append(settings, "log.output")
+	}
+	if !reflect.DeepEqual(current.Log.Redaction, next.Log.Redaction) {
+		settings = append(settings, "log.redaction")
+	}
+	if !reflect.DeepEqual(current.AccessLog, next.AccessLog) {
+		settings = append(settings, "access_log")
+	}
+	if !reflect.DeepEqual(current.Record, next.Record) {
+		settings = append(settings, "record")
+	}
+	if current.Admin != next.Admin {
+		settings = append(settings, "admin")
+	}
+	if current.Auth != next.Auth {
+		settings = append(settings, "auth")
+	}
+	if !reflect.DeepEqual(current.Tenants, next.Tenants) {
+		settings = append(settings, "tenants")
+	}
+	if current.Tracing != next.Tracing {
+		settings = append(settings, "tracing")
+	}
+	return settings
+}
+
+//Print writes the effective config as YAML
+func (cfg *Config) Print(w io.Writer) error {
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
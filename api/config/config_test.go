@@ -0,0 +1,153 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+	env        map[string]string
+	configFile string
+}
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (suite *ConfigTestSuite) SetupTest() {
+	suite.env = make(map[string]string)
+	suite.configFile = filepath.Join(suite.T().TempDir(), "config.yaml")
+}
+
+func (suite *ConfigTestSuite) getenv(name string) string {
+	return suite.env[name]
+}
+
+func (suite *ConfigTestSuite) writeConfig(content string) {
+	suite.Require().Nil(ioutil.WriteFile(suite.configFile, []byte(content), 0600))
+}
+
+func (suite *ConfigTestSuite) load(args ...string) (*Config, error) {
+	return Load(args, suite.getenv, ioutil.Discard)
+}
+
+func (suite *ConfigTestSuite) TestDefaults() {
+	cfg, err := suite.load()
+
+	suite.Nil(err)
+	suite.Equal(Default(), cfg)
+}
+
+func (suite *ConfigTestSuite) TestFileOverridesDefaults() {
+	suite.writeConfig("server:\n  addr: \":9090\"\n  shutdown_timeout: 30s\nlog:\n  level: debug\nrate_limits:\n  /track:\n    rate: 1\n    burst: 5\n")
+	cfg, err := suite.load("--config", suite.configFile)
+
+	suite.Nil(err)
+	suite.Equal(":9090", cfg.Server.Addr)
+	suite.Equal(30*time.Second, cfg.Server.ShutdownTimeout.Duration())
+	suite.Equal(5*time.Second, cfg.Server.DrainDelay.Duration())
+	suite.Equal("debug", cfg.Log.Level)
+	suite.Equal(RateLimitConfig{Rate: 1, Burst: 5}, cfg.RateLimits["/track"])
+}
+
+func (suite *ConfigTestSuite) TestEnvOverridesFileAndFlagsOverrideEnv() {
+	suite.writeConfig("server:\n  addr: \":9090\"\nlog:\n  level: debug\n  format: json\n")
+	suite.env[EnvConfigFile] = suite.configFile
+	suite.env["SERVER_ADDR"] = ":7070"
+	suite.env["LOG_LEVEL"] = "warn"
+	cfg, err := suite.load("--log-level", "error")
+
+	suite.Nil(err)
+	suite.Equal(":7070", cfg.Server.Addr)
+	suite.Equal("error", cfg.Log.Level)
+	suite.Equal("json", cfg.Log.Format)
+}
+
+func (suite *ConfigTestSuite) TestAccessLogListsReplaceDefaults() {
+	suite.writeConfig("access_log:\n  headers: [Referer]\n")
+	suite.env["ACCESS_LOG_REDACT_QUERY"] = "secret, ,key"
+	cfg, err := suite.load("--config", suite.configFile, "--access-log-redact-headers", "")
+
+	suite.Nil(err)
+	suite.Equal([]string{"Referer"}, cfg.AccessLog.Headers)
+	suite.Empty(cfg.AccessLog.RedactHeaders)
+	suite.Equal([]string{"secret", "key"}, cfg.AccessLog.RedactQuery)
+}
+
+func (suite *ConfigTestSuite) TestTenantAirportFilesFromFile() {
+	suite.writeConfig("airports:\n  file: airports.txt\n  tenant_files:\n    cargo: cargo-airports.txt\n")
+	cfg, err := suite.load("--config", suite.configFile)
+
+	suite.Nil(err)
+	suite.Equal("airports.txt", cfg.Airports.File)
+	suite.Equal("cargo-airports.txt", cfg.Airports.TenantFiles["cargo"])
+
+	suite.writeConfig("airports:\n  tenant_files:\n    ../cargo: cargo-airports.txt\n")
+	_, err = suite.load("--config", suite.configFile)
+	suite.NotNil(err)
+}
+
+func (suite *ConfigTestSuite) TestUnknownFileKeyIsRejected() {
+	suite.writeConfig("server:\n  port: 8080\n")
+	_, err := suite.load("--config", suite.configFile)
+
+	suite.NotNil(err)
+}
+
+func (suite *ConfigTestSuite) TestInvalidSettingsAreRejected() {
+	for _, args := range [][]string{
+		{"--addr", "8080"},
+		{"--shutdown-timeout", "0s"},
+		{"--shutdown-timeout", "soon"},
+		{"--gin-mode", "verbose"},
+		{"--trusted-proxies", "10.0.0.0/8,proxy.local"},
+		{"--log-level", "loud"},
+		{"--log-format", "xml"},
+		{"--log-sink", "kafka"},
+		{"--log-redact-mode", "blur"},
+		{"--log-redact-mode", "hash"},
+		{"--log-sink", "file"},
+		{"--log-max-size-mb", "0"},
+		{"--log-max-backups", "many"},
+		{"--admin-addr", ":9090"},
+		{"--admin-addr", ":8080", "--auth-config", "auth.json"},
+		{"--tenants-allowed", "cargo,../passenger"},
+		{"--trace-exporter", "zipkin"},
+		{"--trace-exporter", "file"},
+		{"--trace-sample-rate", "2"},
+		{"extra"},
+	} {
+		_, err := suite.load(args...)
+		suite.NotNil(err, "%v", args)
+	}
+}
+
+func (suite *ConfigTestSuite) TestPrintConfig() {
+	cfg, err := suite.load("--print-config", "--drain-delay", "1s")
+	suite.Nil(err)
+	suite.True(cfg.PrintConfig)
+
+	var out bytes.Buffer
+	suite.Nil(cfg.Print(&out))
+	suite.Contains(out.String(), "drain_delay: 1s")
+	suite.Contains(out.String(), "shutdown_timeout: 20s")
+	suite.NotContains(out.String(), "print")
+}
+
+func (suite *ConfigTestSuite) TestRestartRequiredListsStartUpSettings() {
+	current := Default()
+	next := Default()
+	next.Log.Level = "debug"
+	next.RateLimits["/track"] = RateLimitConfig{Rate: 1, Burst: 1}
+	suite.Empty(RestartRequired(current, next))
+
+	next.Server.Addr = ":9090"
+	next.TLS.CertFile = "server.crt"
+	suite.Equal([]string{"server.addr", "tls"}, RestartRequired(current, next))
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//EnvConfigFile names the config file when the --config flag is not given
+const EnvConfigFile = "CONFIG_FILE"
+
+//setting binds a config field to its environment variable and command line flag
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	value func(cfg *Config) flag.Value
+}
+
+var settings = []setting{
+	{"addr", "SERVER_ADDR", "address the server listens on", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Server.Addr) }},
+	{"shutdown-timeout", "SHUTDOWN_TIMEOUT", "time given to in-flight requests on shutdown", func(cfg *Config) flag.Value { return &cfg.Server.ShutdownTimeout }},
+	{"drain-delay", "DRAIN_DELAY", "time the server keeps serving after it reports not ready", func(cfg *Config) flag.Value { return &cfg.Server.DrainDelay }},
+	{"gin-mode", "GIN_MODE", "gin mode: debug, release or test", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Server.GinMode) }},
+	{"trusted-proxies", "TRUSTED_PROXIES", "comma separated IP addresses or CIDR ranges of the proxies whose X-Forwarded-For header is trusted", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.Server.TrustedProxies) }},
+	{"tls-cert-file", "TLS_CERT_FILE", "path of the PEM server certificate, enables HTTPS", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.TLS.CertFile) }},
+	{"tls-key-file", "TLS_KEY_FILE", "path of the PEM private key of the server certificate", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.TLS.KeyFile) }},
+	{"tls-client-ca-file", "TLS_CLIENT_CA_FILE", "path of the PEM client CA bundle, enables mutual TLS", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.TLS.ClientCAFile) }},
+	{"tls-reload-interval", "TLS_RELOAD_INTERVAL", "how often the TLS files are checked for changes, 0 disables reloading", func(cfg *Config) flag.Value { return &cfg.TLS.ReloadInterval }},
+	{"log-level", "LOG_LEVEL", "log level", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Level) }},
+	{"log-format", "LOG_FORMAT", "log format: text, json, logfmt or ecs", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Format) }},
+	{"log-sink", "LOG_SINK", "where logs are written: stdout, file or syslog", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Output.Sink) }},
+	{"log-file", "LOG_FILE", "file written by the file log sink", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Output.File) }},
+	{"log-max-size-mb", "LOG_MAX_SIZE_MB", "size in megabytes at which the log file is rotated", func(cfg *Config) flag.Value { return (*intValue)(&cfg.Log.Output.MaxSizeMB) }},
+	{"log-max-age-days", "LOG_MAX_AGE_DAYS", "days rotated log files are kept, 0 keeps them forever", func(cfg *Config) flag.Value { return (*intValue)(&cfg.Log.Output.MaxAgeDays) }},
+	{"log-max-backups", "LOG_MAX_BACKUPS", "number of rotated log files kept, 0 keeps them all", func(cfg *Config) flag.Value { return (*intValue)(&cfg.Log.Output.MaxBackups) }},
+	{"log-syslog-tag", "LOG_SYSLOG_TAG", "tag of the entries sent to syslog", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Output.SyslogTag) }},
+	{"log-redact-fields", "LOG_REDACT_FIELDS", "comma separated log fields whose values are redacted", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.Log.Redaction.Fields) }},
+	{"log-redact-mode", "LOG_REDACT_MODE", "redaction mode: mask or hash", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Redaction.Mode) }},
+	{"log-redact-hmac-key-file", "LOG_REDACT_HMAC_KEY_FILE", "file holding the HMAC key of the hash redaction mode", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Log.Redaction.HMACKeyFile) }},
+	{"access-log-headers", "ACCESS_LOG_HEADERS", "comma separated request headers written to the access log", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.AccessLog.Headers) }},
+	{"access-log-redact-headers", "ACCESS_LOG_REDACT_HEADERS", "comma separated request headers logged with their value redacted", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.AccessLog.RedactHeaders) }},
+	{"access-log-redact-query", "ACCESS_LOG_REDACT_QUERY", "comma separated query parameters logged with their value redacted", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.AccessLog.RedactQuery) }},
+	{"record-file", "RECORD_FILE", "JSON lines file the /track requests and responses are appended to, disabled when empty", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Record.File) }},
+	{"record-headers", "RECORD_HEADERS", "comma separated request headers recorded, credentials never are", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.Record.Headers) }},
+	{"admin-addr", "ADMIN_ADDR", "address of the admin API, disabled when empty", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Admin.Addr) }},
+	{"auth-config", "AUTH_CONFIG_FILE", "path of the authentication config file", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Auth.ConfigFile) }},
+	{"airports-file", "AIRPORTS_FILE", "path of the file listing the known airport codes", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Airports.File) }},
+	{"tenants-allowed", "TENANTS_ALLOWED", "comma separated tenants requests may name without authenticating", func(cfg *Config) flag.Value { return (*stringListValue)(&cfg.Tenants.Allowed) }},
+	{"trace-exporter", "TRACE_EXPORTER", "trace exporter: none, stdout or file", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Tracing.Exporter) }},
+	{"trace-file", "TRACE_FILE", "file written by the file trace exporter", func(cfg *Config) flag.Value { return (*stringValue)(&cfg.Tracing.File) }},
+	{"trace-sample-rate", "TRACE_SAMPLE_RATE", "fraction of traces sampled", func(cfg *Config) flag.Value { return (*float64Value)(&cfg.Tracing.SampleRate) }},
+}
+
+//Duration is a time.Duration written as a string such as 20s in YAML and flags
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) Set(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
+
+type stringValue string
+
+func (s *stringValue) String() string {
+	return string(*s)
+}
+
+func (s *stringValue) Set(value string) error {
+	*s = stringValue(value)
+	return nil
+}
+
+type float64Value float64
+
+func (f *float64Value) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 64)
+}
+
+func (f *float64Value) Set(value string) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*f = float64Value(parsed)
+	return nil
+}
+
+type intValue int
+
+func (i *intValue) String() string {
+	return strconv.Itoa(int(*i))
+}
+
+func (i *intValue) Set(value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*i = intValue(parsed)
+	return nil
+}
+
+//recorder keeps the raw value of a flag so it can be applied after the file and the environment
+type recorder struct {
+	name   string
+	values map[string]string
+}
+
+func (r recorder) String() string {
+	if r.values == nil {
+		return ""
+	}
+	return r.values[r.name]
+}
+
+func (r recorder) Set(value string) error {
+	r.values[r.name] = value
+	return nil
+}
+
+//stringListValue is a comma separated list, an empty string is an empty list
+type stringListValue []string
+
+func (s *stringListValue) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListValue) Set(value string) error {
+	*s = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*s = append(*s, item)
+		}
+	}
+	return nil
+}
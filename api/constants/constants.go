@@ -2,9 +2,65 @@ package constants
 
 //Logger constants
 const (
-	ReqID      = "Req-ID"
-	Interface  = "Interface"
-	Method     = "Method"
-	LOGGER_KEY = "api_logger"
-	JSON       = "json"
+	ReqID         = "Req-ID"
+	Interface     = "Interface"
+	Method        = "Method"
+	LOGGER_KEY    = "api_logger"
+	JSON          = "json"
+	TEXT          = "text"
+	LOGFMT        = "logfmt"
+	ECS           = "ecs"
+	Principal     = "Principal"
+	Tenant        = "Tenant"
+	ClientSubject = "Client-Subject"
+	Route         = "Route"
+	ClientIP      = "Client-IP"
+	TraceID       = "Trace-ID"
+	SpanID        = "Span-ID"
+)
+
+//Rate limit constants
+const (
+	APIKeyHeader       = "X-API-Key"
+	RateLimitLimit     = "RateLimit-Limit"
+	RateLimitRemaining = "RateLimit-Remaining"
+	RateLimitReset     = "RateLimit-Reset"
+	RetryAfter         = "Retry-After"
+)
+
+//Auth constants
+const (
+	PRINCIPAL_KEY   = "api_principal"
+	Authorization   = "Authorization"
+	WWWAuthenticate = "WWW-Authenticate"
+)
+
+//Tenant constants
+const (
+	TENANT_KEY   = "api_tenant"
+	TenantHeader = "X-Tenant-ID"
+)
+
+//Metrics constants
+const (
+	ERROR_CODE_KEY = "api_error_code"
+)
+
+//TLS constants
+const (
+	CLIENT_SUBJECT_KEY = "api_client_subject"
+)
+
+//Access log constants
+const (
+	TICKET_COUNT_KEY = "api_ticket_count"
+	AccessMethod     = "method"
+	AccessPath       = "path"
+	AccessStatus     = "status"
+	AccessLatency    = "latency_ms"
+	AccessBytes      = "bytes"
+	AccessTickets    = "tickets"
+	AccessErrorCode  = "error_code"
+	AccessHeaders    = "headers"
+	Redacted         = "[REDACTED]"
 )
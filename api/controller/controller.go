@@ -3,7 +3,6 @@ package controller
 import (
 	"net/http"
 
-	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/dto"
@@ -30,16 +29,21 @@ func NewFlightTrackerController(flightTrackerService service.FlightTrackerServic
 // Find Flight Source And Destination godoc
 // @Tags Find Source And Destination
 // @Accept json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Description Find source and destination
 // @Success 200 {object} []string
 // @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
 // @Failure 422 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Failure 504 {object} errors.ErrorResponse
 // @Param Tickets body dto.Tickets true "request body"
+// @Param X-Tenant-ID header string false "tenant of the request"
 // @Router /track [POST]
 func (ftc flightTrackerController) FindSourceAndDestination(c *gin.Context) {
 	logger := logging.GetLogger(c).
-		WithField(constants.ReqID, requestid.Get(c)).
 		WithField(constants.Interface, "FlightTrackerController").
 		WithField(constants.Method, "FindSourceAndDestination")
 
@@ -48,15 +52,16 @@ func (ftc flightTrackerController) FindSourceAndDestination(c *gin.Context) {
 	//Bind json to tickets object
 	if err := c.ShouldBindJSON(tickets); err != nil {
 		logger.Errorf("ShouldBindJSON - %s", err.Error())
-		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		errors.AbortWithErrorResponse(c, errors.ErrBadRequest)
 		return
 	}
+	c.Set(constants.TICKET_COUNT_KEY, len(tickets.Tickets))
 
 	//validate tickets
 	err := ftc.flightTrackerService.ValidateTickets(c, tickets.Tickets)
 	if err != nil {
 		logger.Errorf("ValidateTickets - %s", err.Error())
-		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		errors.AbortWithErrorResponse(c, err)
 		return
 	}
 
@@ -64,7 +69,7 @@ func (ftc flightTrackerController) FindSourceAndDestination(c *gin.Context) {
 	srcdst, err := ftc.flightTrackerService.FindSourceAndDestination(c, tickets.Tickets)
 	if err != nil {
 		logger.Errorf("FindSourceAndDestination - %s", err.Error())
-		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		errors.AbortWithErrorResponse(c, err)
 		return
 	}
 
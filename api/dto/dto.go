@@ -3,3 +3,11 @@ package dto
 type Tickets struct {
 	Tickets [][]string `json:"tickets"`
 }
+
+//LogSettings changes the log level and format through the admin API. TTL is a duration such as 15m
+//after which the change is reverted, no TTL keeps the change until it is reset
+type LogSettings struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+	TTL    string `json:"ttl"`
+}
@@ -2,6 +2,8 @@ package errors
 
 import (
 	"net/http"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
 )
 
 type ErrorCode string
@@ -10,18 +12,51 @@ const (
 	BadRequest    = "ERR_API_BAD_REQUEST"
 	InvalidTicket = "ERR_API_INVALID_TICKET"
 	UnableToTrack = "ERR_API_UNABLE_TO_TRACK"
+	RateLimited   = "ERR_API_RATE_LIMITED"
+	Unauthorized  = "ERR_API_UNAUTHORIZED"
+	Forbidden     = "ERR_API_FORBIDDEN"
+	InvalidTenant = "ERR_API_INVALID_TENANT"
+	Internal      = "ERR_API_INTERNAL"
+	Timeout       = "ERR_API_TIMEOUT"
+	ClientClosed  = "ERR_API_CLIENT_CLOSED_REQUEST"
 )
 
-var ApiErrors = map[ErrorCode]string{
-	BadRequest:    "Invalid request body",
-	InvalidTicket: "Invalid ticket",
-	UnableToTrack: "Unable to track source and destination for the given tickets",
-}
+//StatusClientClosedRequest is the status logged for requests whose client went away before the
+//response, the client never receives it
+const StatusClientClosedRequest = 499
+
+//ApiErrors holds the English message of each error code
+var ApiErrors = catalogs[DefaultLanguage]
+
+//Reasons of a ValidationError, all but the ticket size are found by the flightpath library
+const (
+	ReasonWrongTicketSize = "WRONG_TICKET_SIZE"
+	ReasonWrongLength     = string(flightpath.ReasonWrongLength)
+	ReasonLowercase       = string(flightpath.ReasonLowercase)
+	ReasonNonAlpha        = string(flightpath.ReasonNonAlpha)
+	ReasonUnknownAirport  = string(flightpath.ReasonUnknownAirport)
+	ReasonSelfLoop        = string(flightpath.ReasonSelfLoop)
+)
+
+//Ticket fields of a ValidationError
+const (
+	FieldOrigin      = flightpath.FieldOrigin
+	FieldDestination = flightpath.FieldDestination
+)
 
 type ErrorResponse struct {
-	HttpStatusCode int       `json:"status"`
-	ErrorCode      ErrorCode `json:"error_code,omitempty"`
-	ErrorMessage   string    `json:"error_message,omitempty"`
+	HttpStatusCode int               `json:"status"`
+	ErrorCode      ErrorCode         `json:"error_code,omitempty"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	Errors         []ValidationError `json:"errors,omitempty"`
+}
+
+//ValidationError describes one problem found in a ticket, Ticket is the index of the ticket in the request
+type ValidationError struct {
+	Ticket int    `json:"ticket"`
+	Field  string `json:"field,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
 }
 
 
@@ -38,6 +73,19 @@ func (e ErrorResponse) Error() string {
 	return e.ErrorMessage
 }
 
+//WithErrors returns a copy of the error response carrying the validation errors
+func (e ErrorResponse) WithErrors(validationErrors []ValidationError) *ErrorResponse {
+	e.Errors = validationErrors
+	return &e
+}
+
 var ErrBadRequest = NewErrorResponse(http.StatusBadRequest, BadRequest, ApiErrors[BadRequest])
 var ErrInvalidTicket = NewErrorResponse(http.StatusBadRequest, InvalidTicket, ApiErrors[InvalidTicket])
 var ErrUnableToTrack = NewErrorResponse(http.StatusUnprocessableEntity, UnableToTrack, ApiErrors[UnableToTrack])
+var ErrRateLimited = NewErrorResponse(http.StatusTooManyRequests, RateLimited, ApiErrors[RateLimited])
+var ErrUnauthorized = NewErrorResponse(http.StatusUnauthorized, Unauthorized, ApiErrors[Unauthorized])
+var ErrForbidden = NewErrorResponse(http.StatusForbidden, Forbidden, ApiErrors[Forbidden])
+var ErrInvalidTenant = NewErrorResponse(http.StatusBadRequest, InvalidTenant, ApiErrors[InvalidTenant])
+var ErrInternal = NewErrorResponse(http.StatusInternalServerError, Internal, ApiErrors[Internal])
+var ErrTimeout = NewErrorResponse(http.StatusGatewayTimeout, Timeout, ApiErrors[Timeout])
+var ErrClientClosed = NewErrorResponse(StatusClientClosedRequest, ClientClosed, ApiErrors[ClientClosed])
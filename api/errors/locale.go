@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//DefaultLanguage is the language of ApiErrors and the fallback of every other catalog
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+//catalogs holds the messages of each error code per language
+var catalogs = loadCatalogs()
+
+var languageMatcher = newLanguageMatcher()
+
+func loadCatalogs() map[string]map[ErrorCode]string {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]map[ErrorCode]string, len(files))
+	for _, file := range files {
+		content, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog := make(map[ErrorCode]string)
+		if err := json.Unmarshal(content, &catalog); err != nil {
+			panic("invalid message catalog " + file.Name() + ": " + err.Error())
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = catalog
+	}
+	return loaded
+}
+
+//newLanguageMatcher matches against the catalog languages, the default language comes first so
+//it is chosen when nothing else matches
+func newLanguageMatcher() language.Matcher {
+	tags := []language.Tag{language.Make(DefaultLanguage)}
+	for lang := range catalogs {
+		if lang != DefaultLanguage {
+			tags = append(tags, language.Make(lang))
+		}
+	}
+	return language.NewMatcher(tags)
+}
+
+//MatchLanguage returns the catalog language which suits the Accept-Language header best
+func MatchLanguage(acceptLanguage string) string {
+	tag, _ := language.MatchStrings(languageMatcher, acceptLanguage)
+	base, _ := tag.Base()
+	if _, ok := catalogs[base.String()]; ok {
+		return base.String()
+	}
+	return DefaultLanguage
+}
+
+//Localize returns a copy of the error response with the message in the given language.
+//Codes missing from that catalog keep their English message
+func (e ErrorResponse) Localize(lang string) *ErrorResponse {
+	if message, ok := catalogs[lang][e.ErrorCode]; ok {
+		e.ErrorMessage = message
+	}
+	return &e
+}
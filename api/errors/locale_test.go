@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocaleTestSuite struct {
+	suite.Suite
+}
+
+func TestLocale(t *testing.T) {
+	suite.Run(t, new(LocaleTestSuite))
+}
+
+func (suite *LocaleTestSuite) TestEveryCatalogHasEveryErrorCode() {
+	suite.Equal("Invalid ticket", ApiErrors[InvalidTicket])
+	for _, lang := range []string{"es", "fr", "hi"} {
+		suite.Contains(catalogs, lang)
+		for code := range ApiErrors {
+			suite.NotEmpty(catalogs[lang][code], "%s has no message for %s", lang, code)
+		}
+	}
+}
+
+func (suite *LocaleTestSuite) TestMatchLanguage() {
+	suite.Equal("es", MatchLanguage("es-MX,es;q=0.9,en;q=0.5"))
+	suite.Equal("fr", MatchLanguage("de;q=0.9, fr;q=0.8"))
+	suite.Equal("hi", MatchLanguage("hi-IN"))
+	suite.Equal(DefaultLanguage, MatchLanguage("de"))
+	suite.Equal(DefaultLanguage, MatchLanguage(""))
+}
+
+func (suite *LocaleTestSuite) TestLocalizeKeepsErrorCode() {
+	localized := ErrInvalidTicket.Localize("fr")
+
+	suite.Equal("Billet invalide", localized.ErrorMessage)
+	suite.Equal(ErrInvalidTicket.ErrorCode, localized.ErrorCode)
+	suite.Equal("Invalid ticket", ErrInvalidTicket.ErrorMessage)
+}
+
+func (suite *LocaleTestSuite) TestLocalizeFallsBackToEnglish() {
+	unknown := NewErrorResponse(http.StatusTeapot, "ERR_API_UNKNOWN", "Unknown error")
+
+	suite.Equal("Unknown error", unknown.Localize("es").ErrorMessage)
+}
+
+func (suite *LocaleTestSuite) TestAbortWithErrorResponseUsesAcceptLanguage() {
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest("POST", "/track", nil)
+	context.Request.Header.Set("Accept-Language", "es-ES")
+
+	AbortWithErrorResponse(context, ErrInvalidTicket)
+
+	var errResponse ErrorResponse
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &errResponse))
+	suite.Equal("es", recorder.Header().Get("Content-Language"))
+	suite.Equal("Billete no válido", errResponse.ErrorMessage)
+	suite.Equal(ErrorCode(InvalidTicket), errResponse.ErrorCode)
+}
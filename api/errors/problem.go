@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+)
+
+const (
+	MIMEJSON        = "application/json"
+	MIMEProblemJSON = "application/problem+json"
+	//ProblemTypeBase is the prefix of the type URI of every problem, it is followed by the error code
+	ProblemTypeBase = "https://github.com/kumareswaramoorthi/flight-paths-tracker#"
+)
+
+//ProblemDetails is the RFC 7807 representation of an ErrorResponse
+type ProblemDetails struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	ErrorCode ErrorCode         `json:"error_code,omitempty"`
+	Errors    []ValidationError `json:"errors,omitempty"`
+}
+
+//NewProblemDetails converts the error response, instance identifies the failed request
+func NewProblemDetails(e *ErrorResponse, instance string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:      ProblemTypeBase + string(e.ErrorCode),
+		Title:     http.StatusText(e.HttpStatusCode),
+		Status:    e.HttpStatusCode,
+		Detail:    e.ErrorMessage,
+		Instance:  instance,
+		ErrorCode: e.ErrorCode,
+		Errors:    e.Errors,
+	}
+}
+
+//AbortWithErrorResponse aborts the request with the error rendered as problem+json when the
+//client prefers it in the Accept header, otherwise with the default ErrorResponse shape.
+//The message is in the language asked for by the Accept-Language header
+func AbortWithErrorResponse(c *gin.Context, e *ErrorResponse) {
+	//the error code is kept for the metrics and access log middlewares
+	c.Set(constants.ERROR_CODE_KEY, e.ErrorCode)
+
+	lang := MatchLanguage(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang)
+	e = e.Localize(lang)
+
+	if !prefersProblemJSON(c.GetHeader("Accept")) {
+		c.AbortWithStatusJSON(e.HttpStatusCode, e)
+		return
+	}
+	c.Abort()
+	c.Render(e.HttpStatusCode, problemRender{problem: NewProblemDetails(e, requestid.Get(c))})
+}
+
+//prefersProblemJSON reports whether application/problem+json is listed in the Accept header
+//with a quality not lower than the one of application/json
+func prefersProblemJSON(accept string) bool {
+	problemQuality, jsonQuality := -1.0, -1.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case MIMEProblemJSON:
+			problemQuality = quality
+		case MIMEJSON:
+			jsonQuality = quality
+		}
+	}
+	return problemQuality > 0 && problemQuality >= jsonQuality
+}
+
+
+//problemRender writes a problem document with the application/problem+json content type
+type problemRender struct {
+	problem *ProblemDetails
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	body, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = []string{MIMEProblemJSON}
+}
@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProblemTestSuite struct {
+	suite.Suite
+	context  *gin.Context
+	recorder *httptest.ResponseRecorder
+}
+
+func TestProblem(t *testing.T) {
+	suite.Run(t, new(ProblemTestSuite))
+}
+
+func (suite *ProblemTestSuite) SetupTest() {
+	suite.recorder = httptest.NewRecorder()
+	suite.context, _ = gin.CreateTestContext(suite.recorder)
+	suite.context.Request, _ = http.NewRequest("POST", "/track", nil)
+	suite.context.Writer.Header().Set("X-Request-ID", "req-1")
+}
+
+func (suite *ProblemTestSuite) abortWithAccept(accept string) {
+	if accept != "" {
+		suite.context.Request.Header.Set("Accept", accept)
+	}
+	AbortWithErrorResponse(suite.context, ErrUnableToTrack)
+}
+
+func (suite *ProblemTestSuite) TestDefaultShapeWithoutAccept() {
+	suite.abortWithAccept("")
+
+	var errResponse ErrorResponse
+	suite.Nil(json.Unmarshal(suite.recorder.Body.Bytes(), &errResponse))
+	suite.Equal(http.StatusUnprocessableEntity, suite.recorder.Code)
+	suite.Contains(suite.recorder.Header().Get("Content-Type"), MIMEJSON)
+	suite.Equal(*ErrUnableToTrack, errResponse)
+	suite.True(suite.context.IsAborted())
+}
+
+func (suite *ProblemTestSuite) TestProblemJSONWhenAccepted() {
+	suite.abortWithAccept(MIMEProblemJSON)
+
+	var problem ProblemDetails
+	suite.Nil(json.Unmarshal(suite.recorder.Body.Bytes(), &problem))
+	suite.Equal(http.StatusUnprocessableEntity, suite.recorder.Code)
+	suite.Equal(MIMEProblemJSON, suite.recorder.Header().Get("Content-Type"))
+	suite.Equal(ProblemDetails{
+		Type:      ProblemTypeBase + UnableToTrack,
+		Title:     "Unprocessable Entity",
+		Status:    http.StatusUnprocessableEntity,
+		Detail:    ApiErrors[UnableToTrack],
+		Instance:  "req-1",
+		ErrorCode: UnableToTrack,
+	}, problem)
+	suite.True(suite.context.IsAborted())
+}
+
+func (suite *ProblemTestSuite) TestPrefersProblemJSON() {
+	suite.True(prefersProblemJSON("application/problem+json, application/json"))
+	suite.True(prefersProblemJSON("application/json;q=0.5, application/problem+json"))
+	suite.False(prefersProblemJSON("application/json, application/problem+json;q=0.5"))
+	suite.False(prefersProblemJSON("application/problem+json;q=0"))
+	suite.False(prefersProblemJSON("*/*"))
+	suite.False(prefersProblemJSON(""))
+}
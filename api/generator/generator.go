@@ -0,0 +1,220 @@
+//Package generator builds shuffled ticket sets with a known answer, the source and destination
+//or the error response of the API, for the tests, the replay and the bench commands
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+)
+
+//Kind is the shape of the generated itinerary
+type Kind string
+
+const (
+	//Linear is a path through distinct airports
+	Linear Kind = "linear"
+	//RoundTrip is a path back to its first airport, it cannot be tracked
+	RoundTrip Kind = "round_trip"
+	//RepeatedAirports is a path visiting one airport twice, it is tracked from its first to its last airport
+	RepeatedAirports Kind = "repeated_airports"
+	//Disjoint is two paths without a common airport, it cannot be tracked
+	Disjoint Kind = "disjoint"
+	//Broken is a linear path with a Defect
+	Broken Kind = "broken"
+)
+
+//Kinds lists every kind
+var Kinds = []Kind{Linear, RoundTrip, RepeatedAirports, Disjoint, Broken}
+
+//Defect is the problem introduced in a Broken itinerary
+type Defect string
+
+const (
+	Lowercase       Defect = "lowercase"
+	NonAlpha        Defect = "non_alpha"
+	WrongLength     Defect = "wrong_length"
+	SelfLoop        Defect = "self_loop"
+	WrongTicketSize Defect = "wrong_ticket_size"
+	DuplicateTicket Defect = "duplicate_ticket"
+	MissingTicket   Defect = "missing_ticket"
+)
+
+//Defects lists every defect
+var Defects = []Defect{Lowercase, NonAlpha, WrongLength, SelfLoop, WrongTicketSize, DuplicateTicket, MissingTicket}
+
+//Fixture is a ticket set with its answer: the source and destination, or the error response
+type Fixture struct {
+	Kind        Kind                  `json:"kind"`
+	Defect      Defect                `json:"defect,omitempty"`
+	Tickets     [][]string            `json:"tickets"`
+	Source      string                `json:"source,omitempty"`
+	Destination string                `json:"destination,omitempty"`
+	Error       *errors.ErrorResponse `json:"error,omitempty"`
+}
+
+//Answer is the response body of the API for the tickets of the fixture
+func (f Fixture) Answer() interface{} {
+	if f.Error != nil {
+		return f.Error
+	}
+	return []string{f.Source, f.Destination}
+}
+
+//Generator builds fixtures from a seed, the same seed gives the same fixtures.
+//A generator is not safe for concurrent use
+type Generator interface {
+	//Generate builds an itinerary of the kind with about length tickets, broken itineraries get a random defect
+	Generate(kind Kind, length int) (Fixture, error)
+	//Break builds a linear itinerary of length tickets with the defect
+	Break(defect Defect, length int) (Fixture, error)
+}
+
+type generator struct {
+	random *rand.Rand
+}
+
+func NewGenerator(seed int64) Generator {
+	return &generator{random: rand.New(rand.NewSource(seed))}
+}
+
+//minLengths are the fewest tickets each kind can be built with
+var minLengths = map[Kind]int{
+	Linear:           1,
+	RoundTrip:        2,
+	RepeatedAirports: 3,
+	Disjoint:         2,
+	Broken:           2,
+}
+
+//maxLength keeps every airport of a path distinct
+const maxLength = 26*26*26 - 2
+
+func (g *generator) Generate(kind Kind, length int) (Fixture, error) {
+	minLength, ok := minLengths[kind]
+	if !ok {
+		return Fixture{}, fmt.Errorf("unknown itinerary kind %q", kind)
+	}
+	if length < minLength || length > maxLength {
+		return Fixture{}, fmt.Errorf("a %s itinerary needs between %d and %d tickets", kind, minLength, maxLength)
+	}
+
+	switch kind {
+	case RoundTrip:
+		airports := g.airports(length)
+		airports = append(airports[:length], airports[0])
+		return g.fixture(Fixture{Kind: kind, Tickets: path(airports), Error: errors.ErrUnableToTrack}), nil
+	case RepeatedAirports:
+		airports := g.airports(length + 1)
+		//the repeated airports are not adjacent, that would be a self loop, nor the two ends, that would be a round trip
+		var i, j int
+		for j-i < 2 || (i == 0 && j == length) {
+			i, j = g.random.Intn(length+1), g.random.Intn(length+1)
+			if i > j {
+				i, j = j, i
+			}
+		}
+		airports[j] = airports[i]
+		return g.fixture(Fixture{Kind: kind, Tickets: path(airports), Source: airports[0], Destination: airports[length]}), nil
+	case Disjoint:
+		airports := g.airports(length + 2)
+		split := 1 + g.random.Intn(length-1)
+		tickets := append(path(airports[:split+1]), path(airports[split+1:])...)
+		return g.fixture(Fixture{Kind: kind, Tickets: tickets, Error: errors.ErrUnableToTrack}), nil
+	case Broken:
+		return g.Break(Defects[g.random.Intn(len(Defects))], length)
+	}
+	airports := g.airports(length + 1)
+	return g.fixture(Fixture{Kind: kind, Tickets: path(airports), Source: airports[0], Destination: airports[length]}), nil
+}
+
+func (g *generator) Break(defect Defect, length int) (Fixture, error) {
+	if length < minLengths[Broken] || length > maxLength {
+		return Fixture{}, fmt.Errorf("a %s itinerary needs between %d and %d tickets", Broken, minLengths[Broken], maxLength)
+	}
+
+	fixture := Fixture{Kind: Broken, Defect: defect}
+	if defect == MissingTicket {
+		//an inner ticket of a longer path is left out so the path splits in two
+		airports := g.airports(length + 2)
+		tickets := path(airports)
+		missing := 1 + g.random.Intn(length-1)
+		fixture.Tickets = append(tickets[:missing], tickets[missing+1:]...)
+		fixture.Error = errors.ErrUnableToTrack
+		return g.fixture(fixture), nil
+	}
+
+	fixture.Tickets = path(g.airports(length + 1))
+	g.shuffle(fixture.Tickets)
+	i := g.random.Intn(length)
+	ticket := fixture.Tickets[i]
+	field, place := errors.FieldOrigin, 0
+	if g.random.Intn(2) == 1 {
+		field, place = errors.FieldDestination, 1
+	}
+
+	var validationError errors.ValidationError
+	switch defect {
+	case Lowercase:
+		ticket[place] = strings.ToLower(ticket[place])
+		validationError = errors.ValidationError{Ticket: i, Field: field, Value: ticket[place], Reason: errors.ReasonLowercase}
+	case NonAlpha:
+		ticket[place] = ticket[place][:1] + fmt.Sprint(g.random.Intn(10)) + ticket[place][2:]
+		validationError = errors.ValidationError{Ticket: i, Field: field, Value: ticket[place], Reason: errors.ReasonNonAlpha}
+	case WrongLength:
+		ticket[place] = ticket[place] + ticket[place][:1]
+		validationError = errors.ValidationError{Ticket: i, Field: field, Value: ticket[place], Reason: errors.ReasonWrongLength}
+	case SelfLoop:
+		ticket[1] = ticket[0]
+		validationError = errors.ValidationError{Ticket: i, Field: errors.FieldDestination, Value: ticket[1], Reason: errors.ReasonSelfLoop}
+	case WrongTicketSize:
+		fixture.Tickets[i] = append(ticket, ticket[0])
+		validationError = errors.ValidationError{Ticket: i, Reason: errors.ReasonWrongTicketSize}
+	case DuplicateTicket:
+		//the copy is inserted at a random place, the airports of the ticket are then visited twice
+		j := g.random.Intn(length + 1)
+		duplicate := []string{ticket[0], ticket[1]}
+		fixture.Tickets = append(fixture.Tickets[:j], append([][]string{duplicate}, fixture.Tickets[j:]...)...)
+		fixture.Error = errors.ErrUnableToTrack
+		return fixture, nil
+	default:
+		return Fixture{}, fmt.Errorf("unknown defect %q", defect)
+	}
+	fixture.Error = errors.ErrInvalidTicket.WithErrors([]errors.ValidationError{validationError})
+	return fixture, nil
+}
+
+//airports returns count distinct airport codes
+func (g *generator) airports(count int) []string {
+	airports := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	for len(airports) < count {
+		code := string([]byte{byte('A' + g.random.Intn(26)), byte('A' + g.random.Intn(26)), byte('A' + g.random.Intn(26))})
+		if !seen[code] {
+			seen[code] = true
+			airports = append(airports, code)
+		}
+	}
+	return airports
+}
+
+//fixture shuffles the tickets of the fixture
+func (g *generator) fixture(fixture Fixture) Fixture {
+	g.shuffle(fixture.Tickets)
+	return fixture
+}
+
+func (g *generator) shuffle(tickets [][]string) {
+	g.random.Shuffle(len(tickets), func(i, j int) { tickets[i], tickets[j] = tickets[j], tickets[i] })
+}
+
+//path returns the tickets flying through the airports in order
+func path(airports []string) [][]string {
+	tickets := make([][]string, len(airports)-1)
+	for i := range tickets {
+		tickets[i] = []string{airports[i], airports[i+1]}
+	}
+	return tickets
+}
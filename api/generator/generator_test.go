@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/service"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
+	"github.com/stretchr/testify/suite"
+)
+
+type GeneratorTestSuite struct {
+	suite.Suite
+	tracker flightpath.Tracker
+}
+
+func TestGenerator(t *testing.T) {
+	suite.Run(t, new(GeneratorTestSuite))
+}
+
+func (suite *GeneratorTestSuite) SetupTest() {
+	suite.tracker = flightpath.NewTracker(nil)
+}
+
+//answer runs the tickets through the validation and tracking of the API
+func (suite *GeneratorTestSuite) answer(tickets [][]string) interface{} {
+	validationErrors, err := service.Validate(context.Background(), suite.tracker, tickets)
+	suite.Require().Nil(err)
+	if len(validationErrors) > 0 {
+		return errors.ErrInvalidTicket.WithErrors(validationErrors)
+	}
+	itinerary, err := suite.tracker.Track(context.Background(), service.ToTickets(tickets))
+	if err != nil {
+		return service.ErrorResponse(err)
+	}
+	return []string{itinerary.Source, itinerary.Destination}
+}
+
+func (suite *GeneratorTestSuite) TestEveryKindHasTheRightAnswer() {
+	fixtureGenerator := NewGenerator(1)
+	for length := 3; length < 30; length++ {
+		for _, kind := range Kinds {
+			fixture, err := fixtureGenerator.Generate(kind, length)
+			suite.Require().Nil(err)
+			suite.Equal(kind, fixture.Kind)
+			suite.Equal(fixture.Answer(), suite.answer(fixture.Tickets), "%+v", fixture)
+		}
+	}
+}
+
+func (suite *GeneratorTestSuite) TestEveryDefectHasTheRightAnswer() {
+	fixtureGenerator := NewGenerator(2)
+	for length := 2; length < 30; length++ {
+		for _, defect := range Defects {
+			fixture, err := fixtureGenerator.Break(defect, length)
+			suite.Require().Nil(err)
+			suite.Equal(defect, fixture.Defect)
+			suite.NotNil(fixture.Error)
+			suite.Equal(fixture.Answer(), suite.answer(fixture.Tickets), "%+v", fixture)
+		}
+	}
+}
+
+func (suite *GeneratorTestSuite) TestShortestItineraries() {
+	fixtureGenerator := NewGenerator(3)
+	for kind, length := range minLengths {
+		fixture, err := fixtureGenerator.Generate(kind, length)
+		suite.Require().Nil(err, kind)
+		suite.Equal(fixture.Answer(), suite.answer(fixture.Tickets), "%+v", fixture)
+
+		_, err = fixtureGenerator.Generate(kind, length-1)
+		suite.NotNil(err, kind)
+	}
+}
+
+func (suite *GeneratorTestSuite) TestSameSeedGivesSameFixtures() {
+	first, second := NewGenerator(7), NewGenerator(7)
+	for _, kind := range Kinds {
+		expected, err := first.Generate(kind, 8)
+		suite.Require().Nil(err)
+		actual, err := second.Generate(kind, 8)
+		suite.Require().Nil(err)
+		suite.Equal(expected, actual)
+	}
+}
+
+func (suite *GeneratorTestSuite) TestUnknownKindAndDefectAreRejected() {
+	fixtureGenerator := NewGenerator(4)
+	_, err := fixtureGenerator.Generate("spiral", 5)
+	suite.NotNil(err)
+	_, err = fixtureGenerator.Break("typo", 5)
+	suite.NotNil(err)
+}
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+//ServerComponent reports whether the server is draining for shutdown
+const ServerComponent = "server"
+
+//Check returns an error while its component cannot serve requests
+type Check func() error
+
+//ComponentStatus is the readiness of one component
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+//Readiness is the body of the readiness probe
+type Readiness struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+//Health holds the state behind the liveness and readiness probes
+type Health struct {
+	draining int32
+	mu       sync.RWMutex
+	checks   map[string]Check
+}
+
+func NewHealth() *Health {
+	h := &Health{checks: make(map[string]Check)}
+	h.AddCheck(ServerComponent, func() error {
+		if atomic.LoadInt32(&h.draining) == 1 {
+			return fmt.Errorf("server is shutting down")
+		}
+		return nil
+	})
+	return h
+}
+
+//AddCheck adds or replaces the readiness check of a component
+func (h *Health) AddCheck(component string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[component] = check
+}
+
+//SetDraining makes the server report not ready until it exits
+func (h *Health) SetDraining() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+//Readiness runs the check of every component
+func (h *Health) Readiness() Readiness {
+	h.mu.RLock()
+	components := make([]string, 0, len(h.checks))
+	for component := range h.checks {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	checks := make([]Check, len(components))
+	for i, component := range components {
+		checks[i] = h.checks[component]
+	}
+	h.mu.RUnlock()
+
+	readiness := Readiness{Status: StatusReady, Components: make(map[string]ComponentStatus, len(components))}
+	for i, component := range components {
+		if err := checks[i](); err != nil {
+			readiness.Status = StatusNotReady
+			readiness.Components[component] = ComponentStatus{Status: StatusDown, Error: err.Error()}
+			continue
+		}
+		readiness.Components[component] = ComponentStatus{Status: StatusUp}
+	}
+	return readiness
+}
+
+//LiveHandler answers as long as the process is able to serve requests
+func (h *Health) LiveHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusUp})
+}
+
+//ReadyHandler answers 503 with the failing components while any component is not ready
+func (h *Health) ReadyHandler(c *gin.Context) {
+	readiness := h.Readiness()
+	if readiness.Status != StatusReady {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+	health *Health
+	router *gin.Engine
+}
+
+func TestHealth(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func (suite *HealthTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.health = NewHealth()
+	suite.router = gin.New()
+	suite.router.GET("/healthz/live", suite.health.LiveHandler)
+	suite.router.GET("/healthz/ready", suite.health.ReadyHandler)
+}
+
+func (suite *HealthTestSuite) ready() (int, Readiness) {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/healthz/ready", nil)
+	suite.router.ServeHTTP(recorder, req)
+
+	var readiness Readiness
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &readiness))
+	return recorder.Code, readiness
+}
+
+func (suite *HealthTestSuite) TestLive() {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/healthz/live", nil)
+	suite.health.SetDraining()
+	suite.router.ServeHTTP(recorder, req)
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(`{"status":"up"}`, recorder.Body.String())
+}
+
+func (suite *HealthTestSuite) TestReadyWhenEveryComponentIsUp() {
+	suite.health.AddCheck("airports", func() error { return nil })
+	code, readiness := suite.ready()
+
+	suite.Equal(http.StatusOK, code)
+	suite.Equal(Readiness{
+		Status: StatusReady,
+		Components: map[string]ComponentStatus{
+			ServerComponent: {Status: StatusUp},
+			"airports":      {Status: StatusUp},
+		},
+	}, readiness)
+}
+
+func (suite *HealthTestSuite) TestNotReadyWhileDraining() {
+	suite.health.SetDraining()
+	code, readiness := suite.ready()
+
+	suite.Equal(http.StatusServiceUnavailable, code)
+	suite.Equal(StatusNotReady, readiness.Status)
+	suite.Equal(StatusDown, readiness.Components[ServerComponent].Status)
+}
+
+func (suite *HealthTestSuite) TestNotReadyUntilComponentIsLoaded() {
+	suite.health.AddCheck("airports", func() error { return fmt.Errorf("airport registry is not loaded") })
+	code, readiness := suite.ready()
+
+	suite.Equal(http.StatusServiceUnavailable, code)
+	suite.Equal(ComponentStatus{Status: StatusDown, Error: "airport registry is not loaded"}, readiness.Components["airports"])
+	suite.Equal(StatusUp, readiness.Components[ServerComponent].Status)
+
+	suite.health.AddCheck("airports", func() error { return nil })
+	code, _ = suite.ready()
+	suite.Equal(http.StatusOK, code)
+}
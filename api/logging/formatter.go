@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/sirupsen/logrus"
+)
+
+//ecsVersion is the version of the Elastic Common Schema written by the ecs formatter
+const ecsVersion = "1.12.0"
+
+//ecsFields maps the fields of the API logger to their Elastic Common Schema name,
+//fields without an ECS counterpart keep their name
+var ecsFields = map[string]string{
+	constants.ReqID:         "http.request.id",
+	constants.Interface:     "log.logger",
+	constants.Method:        "log.origin.function",
+	constants.Principal:     "user.name",
+	constants.Tenant:        "organization.id",
+	constants.ClientSubject: "tls.client.subject",
+	constants.ClientIP:      "client.ip",
+	constants.TraceID:       "trace.id",
+	constants.SpanID:        "span.id",
+	constants.AccessMethod:  "http.request.method",
+	constants.AccessPath:    "url.original",
+	constants.AccessStatus:  "http.response.status_code",
+	constants.AccessBytes:   "http.response.body.bytes",
+	logrus.ErrorKey:         "error.message",
+}
+
+//ValidateFormat reports whether the log format is one SetFormatter accepts
+func ValidateFormat(format string) error {
+	_, err := newFormatter(format)
+	return err
+}
+
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case constants.JSON:
+		return &logrus.JSONFormatter{}, nil
+	case constants.TEXT:
+		return &logrus.TextFormatter{}, nil
+	case constants.LOGFMT:
+		//plain key=value pairs, even on a terminal
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true, TimestampFormat: time.RFC3339Nano, QuoteEmptyFields: true}, nil
+	case constants.ECS:
+		return &ecsFormatter{json: &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  "@timestamp",
+				logrus.FieldKeyLevel: "log.level",
+				logrus.FieldKeyMsg:   "message",
+			},
+		}}, nil
+	}
+	return nil, fmt.Errorf("not a valid log format: %q, use %s, %s, %s or %s", format, constants.TEXT, constants.JSON, constants.LOGFMT, constants.ECS)
+}
+
+//ecsFormatter writes entries as JSON documents following the Elastic Common Schema
+type ecsFormatter struct {
+	json *logrus.JSONFormatter
+}
+
+func (f *ecsFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		if ecsName, ok := ecsFields[k]; ok {
+			k = ecsName
+		}
+		data[k] = v
+	}
+	data["ecs.version"] = ecsVersion
+
+	ecsEntry := *entry
+	ecsEntry.Data = data
+	return f.json.Format(&ecsEntry)
+}
@@ -3,9 +3,11 @@ package logging
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 
+	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
 	"github.com/sirupsen/logrus"
@@ -35,8 +37,9 @@ type ApiLoggerEntry interface {
 	WithField(key string, value interface{}) *apiLoggerEntry
 	WithFields(fields ApiLoggerFields) *apiLoggerEntry
 	WithContext(ctx context.Context) *apiLoggerEntry
-	SetFormatter(format string)
+	SetFormatter(format string) error
 	SetLevel(lvl string) error
+	SetOutput(output Output) (io.Closer, error)
 
 	Tracef(format string, value ...interface{})
 	Debugf(format string, value ...interface{})
@@ -128,23 +131,33 @@ func (l apiLoggerEntry) WithFields(fields ApiLoggerFields) *apiLoggerEntry {
 	return &apiLoggerEntry{context: l.context, stdEntry: newEntry, Data: data, err: fieldErr}
 }
 
+//WithContext binds the entry to the context, the IDs of the span of the context are added as fields
 func (l apiLoggerEntry) WithContext(ctx context.Context) *apiLoggerEntry {
-	return &apiLoggerEntry{
+	entry := &apiLoggerEntry{
 		context:  ctx,
 		stdEntry: l.stdEntry.WithContext(ctx),
 		Data:     l.Data,
 		err:      l.err,
 	}
+	if span := getSpan(*entry); span != nil {
+		spanContext := span.SpanContext()
+		return entry.WithFields(ApiLoggerFields{
+			constants.TraceID: spanContext.TraceID.String(),
+			constants.SpanID:  spanContext.SpanID.String(),
+		})
+	}
+	return entry
 }
 
 //Happens only for logrus logs and not span logs
-func (l apiLoggerEntry) SetFormatter(format string) {
-	var formatter logrus.Formatter
-	switch format {
-	case constants.JSON:
-		formatter = &logrus.JSONFormatter{}
-		l.stdEntry.Logger.SetFormatter(formatter)
+func (l apiLoggerEntry) SetFormatter(format string) error {
+	formatter, err := newFormatter(format)
+	if err != nil {
+		return err
 	}
+	//values are redacted before any formatter sees them
+	l.stdEntry.Logger.SetFormatter(&redactingFormatter{next: formatter})
+	return nil
 }
 
 func (l apiLoggerEntry) SetLevel(lvl string) error {
@@ -324,6 +337,9 @@ func logToSpan(l apiLoggerEntry, level logrus.Level, printType string, format st
 			formattedValue = formattedValue[:len(formattedValue)-1]
 		}
 
+		if r := getRedactor(); r != nil {
+			formattedValue = r.message(formattedValue)
+		}
 		span.Annotate(getSpanAttributes(l, level), formattedValue)
 	}
 }
@@ -331,7 +347,11 @@ func logToSpan(l apiLoggerEntry, level logrus.Level, printType string, format st
 func getSpanAttributes(l apiLoggerEntry, level logrus.Level) []trace.Attribute {
 	var logAttributes []trace.Attribute
 	logAttributes = append(logAttributes, trace.StringAttribute("level", fmt.Sprint(level)))
-	for key, data := range l.Data {
+	data := map[string]interface{}(l.Data)
+	if r := getRedactor(); r != nil {
+		data = r.data(data)
+	}
+	for key, data := range data {
 		logAttributes = append(logAttributes, trace.StringAttribute(key, fmt.Sprint(data)))
 	}
 	return logAttributes
@@ -367,9 +387,18 @@ func GetLogger(c context.Context) *apiLoggerEntry {
 	return NewLoggerEntry()
 }
 
+//LoggingMiddleware gives every request a child of the global logger carrying its request ID, route
+//and client IP, which GetLogger returns. It must run after the request ID middleware
 func LoggingMiddleware(apiLogger *apiLoggerEntry) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set(constants.LOGGER_KEY, apiLogger)
+		fields := ApiLoggerFields{
+			constants.ReqID:    requestid.Get(c),
+			constants.ClientIP: c.ClientIP(),
+		}
+		if route := c.FullPath(); route != "" {
+			fields[constants.Route] = route
+		}
+		c.Set(constants.LOGGER_KEY, apiLogger.WithFields(fields))
 		c.Next()
 	}
 }
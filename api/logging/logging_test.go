@@ -0,0 +1,179 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type LoggingTestSuite struct {
+	suite.Suite
+	logger *apiLoggerEntry
+	out    *bytes.Buffer
+}
+
+func TestLogging(t *testing.T) {
+	suite.Run(t, new(LoggingTestSuite))
+}
+
+func (suite *LoggingTestSuite) SetupTest() {
+	suite.out = new(bytes.Buffer)
+	stdLogger := logrus.New()
+	stdLogger.SetOutput(suite.out)
+	suite.logger = newLoggerEntry(context.TODO(), stdLogger.WithContext(context.TODO()))
+}
+
+func (suite *LoggingTestSuite) TearDownTest() {
+	activeRedactor.Store((*redactor)(nil))
+}
+
+func (suite *LoggingTestSuite) logJSON(log func()) map[string]interface{} {
+	suite.Require().Nil(suite.logger.SetFormatter(constants.JSON))
+	log()
+	var document map[string]interface{}
+	suite.Require().Nil(json.Unmarshal(suite.out.Bytes(), &document))
+	return document
+}
+
+func (suite *LoggingTestSuite) TestRedactionMasksFieldsAndPatterns() {
+	suite.Require().Nil(SetRedaction(Redaction{Fields: []string{"PNR", "passenger_id"}, Patterns: []string{`[A-Z]{6}-\d+`}, Mode: RedactMask}))
+
+	document := suite.logJSON(func() {
+		suite.logger.WithField("pnr", "ABC123").WithField("passenger_id", 42).WithField("note", "booked ABCDEF-1").Infof("tracking %s", "XYZXYZ-7")
+	})
+
+	suite.Equal(constants.Redacted, document["pnr"])
+	suite.Equal(constants.Redacted, document["passenger_id"])
+	suite.Equal("booked [REDACTED]", document["note"])
+	suite.Equal("tracking [REDACTED]", document["msg"])
+}
+
+func (suite *LoggingTestSuite) TestRedactionHashesWithKeyedHMAC() {
+	suite.Require().Nil(SetRedaction(Redaction{Fields: []string{"pnr"}, Mode: RedactHash, HMACKey: []byte("0123456789abcdef")}))
+
+	first := suite.logJSON(func() { suite.logger.WithField("pnr", "ABC123").Info("first") })
+	suite.out.Reset()
+	second := suite.logJSON(func() { suite.logger.WithField("pnr", "ABC123").Info("second") })
+
+	suite.Regexp(`^hmac:[0-9a-f]{16}$`, first["pnr"])
+	suite.Equal(first["pnr"], second["pnr"])
+	suite.NotContains(suite.out.String(), "ABC123")
+}
+
+func (suite *LoggingTestSuite) TestRedactionAppliesToSpanAnnotations() {
+	suite.Require().Nil(SetRedaction(Redaction{Fields: []string{"pnr"}, Patterns: []string{`secret`}, Mode: RedactMask}))
+
+	attributes := getSpanAttributes(*suite.logger.WithField("pnr", "ABC123").WithField("other", "kept"), logrus.InfoLevel)
+
+	values := make(map[string]interface{})
+	for _, attribute := range attributes {
+		values[attribute.Key()] = attribute.Value()
+	}
+	suite.Equal(constants.Redacted, values["pnr"])
+	suite.Equal("kept", values["other"])
+}
+
+func (suite *LoggingTestSuite) TestRedactBody() {
+	suite.Require().Nil(SetRedaction(Redaction{Fields: []string{"pnr"}, Patterns: []string{`secret`}, Mode: RedactMask}))
+
+	suite.JSONEq(`{"tickets": [["SFO", "EWR"]], "passenger": {"PNR": "[REDACTED]", "note": "a [REDACTED]"}}`,
+		string(RedactBody([]byte(`{"tickets": [["SFO", "EWR"]], "passenger": {"PNR": "ABC123", "note": "a secret"}}`))))
+	suite.Equal("not [REDACTED] json", string(RedactBody([]byte("not secret json"))))
+}
+
+func (suite *LoggingTestSuite) TestInvalidRedactionIsRejected() {
+	suite.NotNil(SetRedaction(Redaction{Mode: "blur"}))
+	suite.NotNil(SetRedaction(Redaction{Mode: RedactHash, HMACKey: []byte("short")}))
+	suite.NotNil(SetRedaction(Redaction{Patterns: []string{"("}, Mode: RedactMask}))
+}
+
+func (suite *LoggingTestSuite) TestLoggingMiddlewareScopesLoggerToRequest() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.New())
+	router.Use(LoggingMiddleware(suite.logger))
+	var data ApiLoggerFields
+	router.GET("/track/:id", func(c *gin.Context) {
+		data = GetLogger(c).Data
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/track/7", nil)
+	req.Header.Set("X-Request-ID", "42")
+	req.RemoteAddr = "10.0.0.1:4000"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	suite.Equal("42", data[constants.ReqID])
+	suite.Equal("/track/:id", data[constants.Route])
+	suite.Equal("10.0.0.1", data[constants.ClientIP])
+}
+
+func (suite *LoggingTestSuite) TestWithContextAddsSpanIDs() {
+	ctx, span := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+
+	data := suite.logger.WithContext(ctx).Data
+
+	suite.Equal(span.SpanContext().TraceID.String(), data[constants.TraceID])
+	suite.Equal(span.SpanContext().SpanID.String(), data[constants.SpanID])
+	suite.NotContains(suite.logger.WithContext(context.Background()).Data, constants.TraceID)
+}
+
+func (suite *LoggingTestSuite) TestUnknownFormatIsRejected() {
+	suite.NotNil(suite.logger.SetFormatter("xml"))
+	suite.NotNil(ValidateFormat(""))
+}
+
+func (suite *LoggingTestSuite) TestLogfmtFormat() {
+	suite.Require().Nil(suite.logger.SetFormatter(constants.LOGFMT))
+
+	suite.logger.WithField(constants.Tenant, "").Info("tracked")
+
+	suite.Contains(suite.out.String(), `level=info msg=tracked Tenant=""`)
+}
+
+func (suite *LoggingTestSuite) TestECSFormat() {
+	suite.Require().Nil(suite.logger.SetFormatter(constants.ECS))
+
+	suite.logger.WithField(constants.ReqID, "42").WithField("custom", 1).Warn("slow")
+
+	var document map[string]interface{}
+	suite.Require().Nil(json.Unmarshal(suite.out.Bytes(), &document))
+	suite.Equal("slow", document["message"])
+	suite.Equal("warning", document["log.level"])
+	suite.Equal(ecsVersion, document["ecs.version"])
+	suite.Equal("42", document["http.request.id"])
+	suite.Equal(float64(1), document["custom"])
+	suite.Contains(document, "@timestamp")
+}
+
+func (suite *LoggingTestSuite) TestFileSink() {
+	file := filepath.Join(suite.T().TempDir(), "api.log")
+	closer, err := suite.logger.SetOutput(Output{Sink: SinkFile, File: file, MaxSizeMB: 1})
+	suite.Require().Nil(err)
+
+	suite.logger.Info("to file")
+	suite.Nil(closer.Close())
+
+	content, err := ioutil.ReadFile(file)
+	suite.Nil(err)
+	suite.Contains(string(content), "to file")
+}
+
+func (suite *LoggingTestSuite) TestInvalidSinksAreRejected() {
+	_, err := suite.logger.SetOutput(Output{Sink: "kafka"})
+	suite.NotNil(err)
+	_, err = suite.logger.SetOutput(Output{Sink: SinkFile})
+	suite.NotNil(err)
+}
@@ -0,0 +1,190 @@
+package logging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/sirupsen/logrus"
+)
+
+//Redaction modes
+const (
+	RedactMask = "mask"
+	RedactHash = "hash"
+)
+
+//hashPrefix marks hashed values, only the start of the HMAC is kept
+const (
+	hashPrefix = "hmac:"
+	hashLength = 16
+)
+
+//Redaction hides personal data from log entries and span annotations. The values of the Fields,
+//whatever their type, and the parts of messages and string values matching one of the Patterns are
+//replaced by [REDACTED], or by a keyed HMAC in hash mode so equal values can still be correlated
+type Redaction struct {
+	Fields   []string
+	Patterns []string
+	Mode     string
+	HMACKey  []byte
+}
+
+type redactor struct {
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+	hash     bool
+	key      []byte
+}
+
+//activeRedactor is shared by every logger, as spans are
+var activeRedactor atomic.Value
+
+//SetRedaction applies the redaction to every entry logged from now on
+func SetRedaction(redaction Redaction) error {
+	r := &redactor{
+		fields: make(map[string]bool, len(redaction.Fields)),
+		key:    redaction.HMACKey,
+	}
+	for _, field := range redaction.Fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	for _, pattern := range redaction.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		r.patterns = append(r.patterns, compiled)
+	}
+	switch redaction.Mode {
+	case RedactMask:
+	case RedactHash:
+		if len(redaction.HMACKey) < 16 {
+			return fmt.Errorf("the %s redaction mode needs an HMAC key of at least 16 bytes", RedactHash)
+		}
+		r.hash = true
+	default:
+		return fmt.Errorf("not a valid redaction mode: %q, use %s or %s", redaction.Mode, RedactMask, RedactHash)
+	}
+	activeRedactor.Store(r)
+
+	//the formatter in use is wrapped so entries are redacted before they are formatted
+	logger := logrus.StandardLogger()
+	if _, ok := logger.Formatter.(*redactingFormatter); !ok {
+		logger.SetFormatter(&redactingFormatter{next: logger.Formatter})
+	}
+	return nil
+}
+
+func getRedactor() *redactor {
+	r, _ := activeRedactor.Load().(*redactor)
+	return r
+}
+
+func (r *redactor) value(value string) string {
+	if !r.hash {
+		return constants.Redacted
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+func (r *redactor) message(message string) string {
+	for _, pattern := range r.patterns {
+		message = pattern.ReplaceAllStringFunc(message, r.value)
+	}
+	return message
+}
+
+//data returns a redacted copy of the fields
+func (r *redactor) data(data map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		switch value := v.(type) {
+		case string:
+			if r.fields[strings.ToLower(k)] {
+				v = r.value(value)
+			} else {
+				v = r.message(value)
+			}
+		case error:
+			if r.fields[strings.ToLower(k)] {
+				v = r.value(value.Error())
+			} else {
+				v = r.message(value.Error())
+			}
+		default:
+			if r.fields[strings.ToLower(k)] {
+				v = r.value(fmt.Sprint(value))
+			}
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+//RedactBody redacts a request or response body with the active redaction: the values of the Fields
+//at any depth of a JSON body, the parts matching one of the Patterns in any other string
+func RedactBody(body []byte) []byte {
+	r := getRedactor()
+	if r == nil || len(body) == 0 {
+		return body
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []byte(r.message(string(body)))
+	}
+	redacted, err := json.Marshal(r.document(document))
+	if err != nil {
+		return []byte(r.message(string(body)))
+	}
+	return redacted
+}
+
+//document returns a redacted copy of a decoded JSON document
+func (r *redactor) document(document interface{}) interface{} {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			if r.fields[strings.ToLower(k)] {
+				redacted[k] = r.value(fmt.Sprint(v))
+			} else {
+				redacted[k] = r.document(v)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for i, v := range value {
+			redacted[i] = r.document(v)
+		}
+		return redacted
+	case string:
+		return r.message(value)
+	}
+	return document
+}
+
+//redactingFormatter redacts entries before the wrapped formatter sees them
+type redactingFormatter struct {
+	next logrus.Formatter
+}
+
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	r := getRedactor()
+	if r == nil {
+		return f.next.Format(entry)
+	}
+	redacted := *entry
+	redacted.Data = r.data(entry.Data)
+	redacted.Message = r.message(entry.Message)
+	return f.next.Format(&redacted)
+}
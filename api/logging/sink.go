@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+//Log sinks
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+)
+
+//Output is where log entries are written. Files are rotated once they reach MaxSizeMB,
+//rotated files are removed after MaxAgeDays or when there are more than MaxBackups of them
+type Output struct {
+	Sink       string
+	File       string
+	MaxSizeMB  int
+	MaxAgeDays int
+	MaxBackups int
+	SyslogTag  string
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+//SetOutput sends the entries of the logger to the sink of the output. The returned closer
+//flushes and releases the sink, it is meant to be called on shutdown
+func (l apiLoggerEntry) SetOutput(output Output) (io.Closer, error) {
+	logger := l.stdEntry.Logger
+	switch output.Sink {
+	case SinkStdout:
+		logger.SetOutput(os.Stdout)
+		return nopCloser{}, nil
+	case SinkFile:
+		if output.File == "" {
+			return nil, fmt.Errorf("the %s log sink needs a file", SinkFile)
+		}
+		rotator := &lumberjack.Logger{
+			Filename:   output.File,
+			MaxSize:    output.MaxSizeMB,
+			MaxAge:     output.MaxAgeDays,
+			MaxBackups: output.MaxBackups,
+		}
+		logger.SetOutput(rotator)
+		return rotator, nil
+	case SinkSyslog:
+		hook, err := newSyslogHook(output.SyslogTag)
+		if err != nil {
+			return nil, err
+		}
+		//entries are only written through the syslog hook
+		logger.AddHook(hook)
+		logger.SetOutput(ioutil.Discard)
+		return hook, nil
+	}
+	return nil, fmt.Errorf("not a valid log sink: %q, use %s, %s or %s", output.Sink, SinkStdout, SinkFile, SinkSyslog)
+}
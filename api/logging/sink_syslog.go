@@ -0,0 +1,27 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"log/syslog"
+
+	lsyslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+type syslogHook struct {
+	*lsyslog.SyslogHook
+}
+
+func (h syslogHook) Close() error {
+	return h.Writer.Close()
+}
+
+//newSyslogHook connects to the local syslog daemon, the level of each entry sets its severity
+func newSyslogHook(tag string) (syslogHook, error) {
+	hook, err := lsyslog.NewSyslogHook("", "", syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return syslogHook{}, err
+	}
+	return syslogHook{hook}, nil
+}
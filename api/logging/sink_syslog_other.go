@@ -0,0 +1,22 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package logging
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+type syslogHook struct {
+	logrus.Hook
+}
+
+func (h syslogHook) Close() error {
+	return nil
+}
+
+func newSyslogHook(tag string) (syslogHook, error) {
+	return syslogHook{}, fmt.Errorf("the %s log sink is not supported on this platform", SinkSyslog)
+}
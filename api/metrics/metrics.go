@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "flight_paths_tracker"
+
+//label of requests which did not match any route
+const unmatchedRoute = "unmatched"
+
+//ticketBuckets suit both single itineraries and large batches of tickets
+var ticketBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 5000}
+
+//Metrics holds the collectors of the API on a registry of its own
+type Metrics struct {
+	registry          *prometheus.Registry
+	requestDuration   *prometheus.HistogramVec
+	requestsInFlight  prometheus.Gauge
+	errorsTotal       *prometheus.CounterVec
+	ticketsPerRequest *prometheus.HistogramVec
+	itineraryLength   *prometheus.HistogramVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests per route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status", "tenant"}),
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		errorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of error responses per error code.",
+		}, []string{"error_code", "tenant"}),
+		ticketsPerRequest: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tickets_per_request",
+			Help:      "Number of tickets sent in each tracking request.",
+			Buckets:   ticketBuckets,
+		}, []string{"tenant"}),
+		itineraryLength: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "itinerary_length",
+			Help:      "Number of flights in each tracked itinerary.",
+			Buckets:   ticketBuckets,
+		}, []string{"tenant"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.requestsInFlight,
+		m.errorsTotal,
+		m.ticketsPerRequest,
+		m.itineraryLength,
+	)
+	return m
+}
+
+//Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+//MetricsMiddleware records the latency, status and error code of every request
+func MetricsMiddleware(m *Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m.requestsInFlight.Inc()
+		defer m.requestsInFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		//only authenticated and allowed tenants are resolved, which bounds the tenant label values
+		tenantID := string(tenant.Get(c))
+		m.requestDuration.
+			WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status()), tenantID).
+			Observe(time.Since(start).Seconds())
+
+		if errorCode, ok := c.Get(constants.ERROR_CODE_KEY); ok {
+			m.errorsTotal.WithLabelValues(string(errorCode.(errors.ErrorCode)), tenantID).Inc()
+		}
+	}
+}
+
+//ObserveTickets records the number of tickets of a tracking request
+func (m *Metrics) ObserveTickets(c *gin.Context, tickets int) {
+	m.ticketsPerRequest.WithLabelValues(string(tenant.Get(c))).Observe(float64(tickets))
+}
+
+//ObserveItinerary records the number of flights of a tracked itinerary
+func (m *Metrics) ObserveItinerary(c *gin.Context, flights int) {
+	m.itineraryLength.WithLabelValues(string(tenant.Get(c))).Observe(float64(flights))
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	metrics *Metrics
+	router  *gin.Engine
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
+
+func (suite *MetricsTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.metrics = NewMetrics()
+	suite.router = gin.New()
+	suite.router.Use(MetricsMiddleware(suite.metrics))
+	suite.router.GET("/metrics", suite.metrics.Handler())
+	suite.router.POST("/track", tenant.TenantMiddleware([]string{"cargo"}), func(c *gin.Context) {
+		suite.metrics.ObserveTickets(c, 4)
+		suite.metrics.ObserveItinerary(c, 4)
+		c.Status(http.StatusOK)
+	})
+	suite.router.POST("/fail", func(c *gin.Context) {
+		errors.AbortWithErrorResponse(c, errors.ErrUnableToTrack)
+	})
+}
+
+func (suite *MetricsTestSuite) request(method, path string) *httptest.ResponseRecorder {
+	return suite.requestTenant(method, path, "")
+}
+
+func (suite *MetricsTestSuite) requestTenant(method, path string, tenantID string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	if tenantID != "" {
+		req.Header.Set(constants.TenantHeader, tenantID)
+	}
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *MetricsTestSuite) TestMetricsAreExposed() {
+	suite.request("POST", "/track")
+	suite.request("POST", "/fail")
+	suite.request("GET", "/unknown")
+
+	recorder := suite.request("GET", "/metrics")
+	body := recorder.Body.String()
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(body, `flight_paths_tracker_http_request_duration_seconds_count{method="POST",route="/track",status="200",tenant="default"} 1`)
+	suite.Contains(body, `flight_paths_tracker_http_request_duration_seconds_count{method="GET",route="unmatched",status="404",tenant="default"} 1`)
+	suite.Contains(body, `flight_paths_tracker_errors_total{error_code="ERR_API_UNABLE_TO_TRACK",tenant="default"} 1`)
+	suite.Contains(body, `flight_paths_tracker_tickets_per_request_sum{tenant="default"} 4`)
+	suite.Contains(body, `flight_paths_tracker_itinerary_length_count{tenant="default"} 1`)
+	suite.Contains(body, `flight_paths_tracker_http_requests_in_flight 1`)
+	suite.Contains(body, "go_goroutines")
+}
+
+func (suite *MetricsTestSuite) TestTenantLabelsAreLimitedToResolvedTenants() {
+	suite.requestTenant("POST", "/track", "cargo")
+	suite.requestTenant("POST", "/track", "tenant-1")
+	suite.requestTenant("POST", "/track", "tenant-2")
+
+	body := suite.request("GET", "/metrics").Body.String()
+
+	suite.Contains(body, `flight_paths_tracker_tickets_per_request_count{tenant="cargo"} 1`)
+	suite.Contains(body, `flight_paths_tracker_tickets_per_request_count{tenant="default"} 2`)
+	suite.NotContains(body, `tenant="tenant-1"`)
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/auth"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tlsconfig"
+)
+
+//RateLimitMiddleware limits the requests of each client on a route, clients are identified by the
+//authenticated principal or their verified client certificate and fall back to the client IP otherwise
+func RateLimitMiddleware(store Store, limits RouteLimits, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.GetLogger(c).
+			WithField(constants.Interface, "RateLimitMiddleware").
+			WithField(constants.Method, route)
+
+		//buckets are kept apart per tenant so tenants never share a limit, the tenant is resolved from
+		//the principal or the allowed tenants only so clients cannot open buckets by naming new tenants
+		result := store.Take(string(tenant.Get(c))+":"+route+":"+clientKey(c), limits.Get(route))
+
+		c.Header(constants.RateLimitLimit, strconv.Itoa(result.Limit))
+		c.Header(constants.RateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Header(constants.RateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header(constants.RetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			logger.Warnf("Rate limit exceeded - %s", errors.ErrRateLimited.Error())
+			errors.AbortWithErrorResponse(c, errors.ErrRateLimited)
+			return
+		}
+		c.Next()
+	}
+}
+
+//AuthFailureMiddleware limits the failed authentications of each client IP on a route with the limit
+//of the route. It runs before AuthMiddleware so keys and tokens cannot be guessed without limit,
+//only the requests answered with 401 take a token
+func AuthFailureMiddleware(store Store, limits RouteLimits, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.GetLogger(c).
+			WithField(constants.Interface, "AuthFailureMiddleware").
+			WithField(constants.Method, route)
+
+		//the client IP is the address of the peer, or the one given by a trusted proxy
+		key := "auth-failures:" + route + ":ip:" + c.ClientIP()
+		limit := limits.Get(route)
+		if result := store.Peek(key, limit); !result.Allowed {
+			c.Header(constants.RetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			logger.Warnf("Too many failed authentications - %s", errors.ErrRateLimited.Error())
+			errors.AbortWithErrorResponse(c, errors.ErrRateLimited)
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			store.Take(key, limit)
+		}
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if principal, ok := auth.GetPrincipal(c); ok {
+		return "principal:" + principal.Subject
+	}
+	if subject, ok := tlsconfig.GetClientSubject(c); ok {
+		return "cert:" + subject
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
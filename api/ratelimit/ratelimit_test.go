@@ -0,0 +1,228 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/auth"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+	now    time.Time
+	store  *memoryStore
+	limits *Limits
+	router *gin.Engine
+}
+
+func TestRateLimit(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
+
+func (suite *RateLimitTestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.store = NewMemoryStore().(*memoryStore)
+	suite.store.now = func() time.Time { return suite.now }
+	suite.limits = NewLimits(map[string]Limit{"/track": {Rate: 1, Burst: 2}}, Limit{Rate: 1, Burst: 1})
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.POST("/track", authenticated, tenant.TenantMiddleware([]string{"cargo", "passenger"}), RateLimitMiddleware(suite.store, suite.limits, "/track"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+}
+
+//authenticated stands in for AuthMiddleware, the subject header names the principal
+func authenticated(c *gin.Context) {
+	if subject := c.GetHeader(testSubjectHeader); subject != "" {
+		c.Set(constants.PRINCIPAL_KEY, &auth.Principal{Subject: subject, Tenant: c.GetHeader(constants.TenantHeader)})
+	}
+}
+
+const testSubjectHeader = "X-Test-Subject"
+
+func (suite *RateLimitTestSuite) track(apiKey string) *httptest.ResponseRecorder {
+	return suite.trackTenant(apiKey, "")
+}
+
+func (suite *RateLimitTestSuite) trackAs(subject string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/track", nil)
+	req.Header.Set(testSubjectHeader, subject)
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *RateLimitTestSuite) trackTenant(apiKey string, tenantID string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/track", nil)
+	if apiKey != "" {
+		req.Header.Set(constants.APIKeyHeader, apiKey)
+	}
+	if tenantID != "" {
+		req.Header.Set(constants.TenantHeader, tenantID)
+	}
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *RateLimitTestSuite) TestTakeAllowsUpToBurst() {
+	limit := Limit{Rate: 1, Burst: 2}
+
+	suite.True(suite.store.Take("client", limit).Allowed)
+	suite.True(suite.store.Take("client", limit).Allowed)
+
+	result := suite.store.Take("client", limit)
+	suite.False(result.Allowed)
+	suite.Equal(0, result.Remaining)
+	suite.Equal(time.Second, result.RetryAfter)
+}
+
+func (suite *RateLimitTestSuite) TestTakeRefillsOverTime() {
+	limit := Limit{Rate: 1, Burst: 1}
+
+	suite.True(suite.store.Take("client", limit).Allowed)
+	suite.False(suite.store.Take("client", limit).Allowed)
+
+	suite.now = suite.now.Add(time.Second)
+	suite.True(suite.store.Take("client", limit).Allowed)
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareSetsRateLimitHeaders() {
+	recorder := suite.track("")
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("2", recorder.Header().Get(constants.RateLimitLimit))
+	suite.Equal("1", recorder.Header().Get(constants.RateLimitRemaining))
+	suite.Equal("1", recorder.Header().Get(constants.RateLimitReset))
+	suite.Empty(recorder.Header().Get(constants.RetryAfter))
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareRejectsWhenLimitExceeded() {
+	suite.track("")
+	suite.track("")
+	recorder := suite.track("")
+
+	var errResponse errors.ErrorResponse
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &errResponse))
+	suite.Equal(http.StatusTooManyRequests, recorder.Code)
+	suite.Equal("1", recorder.Header().Get(constants.RetryAfter))
+	suite.Equal(errors.ErrorCode(errors.RateLimited), errResponse.ErrorCode)
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareLimitsEachPrincipalSeparately() {
+	suite.trackAs("alice")
+	suite.trackAs("alice")
+
+	suite.Equal(http.StatusTooManyRequests, suite.trackAs("alice").Code)
+	suite.Equal(http.StatusOK, suite.trackAs("bob").Code)
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareIgnoresUnverifiedAPIKeys() {
+	suite.track("key-1")
+	suite.track("key-2")
+
+	//a new key on each request still takes from the bucket of the client IP
+	suite.Equal(http.StatusTooManyRequests, suite.track("key-3").Code)
+}
+
+func (suite *RateLimitTestSuite) TestStoreDropsRefilledBuckets() {
+	limit := Limit{Rate: 1, Burst: 2}
+	suite.store.Take("idle", limit)
+	suite.store.Take("busy", limit)
+	suite.store.Take("busy", limit)
+
+	//after a sweep interval the idle bucket is full again, the busy one is not yet
+	suite.now = suite.now.Add(sweepInterval)
+	suite.store.buckets["busy"].fullAt = suite.now.Add(time.Second)
+	suite.store.Take("new", limit)
+
+	suite.NotContains(suite.store.buckets, "idle")
+	suite.Contains(suite.store.buckets, "busy")
+	suite.Contains(suite.store.buckets, "new")
+	//a dropped bucket starts again full
+	suite.Equal(1, suite.store.Take("idle", limit).Remaining)
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareLimitsEachTenantSeparately() {
+	suite.trackTenant("key-1", "cargo")
+	suite.trackTenant("key-1", "cargo")
+
+	suite.Equal(http.StatusTooManyRequests, suite.trackTenant("key-1", "cargo").Code)
+	suite.Equal(http.StatusOK, suite.trackTenant("key-1", "passenger").Code)
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareSharesBucketOfTenantsNotAllowed() {
+	suite.trackTenant("", "tenant-1")
+	suite.trackTenant("", "tenant-2")
+
+	//tenants which are neither allowed nor authenticated all take from the default tenant bucket
+	suite.Equal(http.StatusTooManyRequests, suite.trackTenant("", "tenant-3").Code)
+}
+
+func (suite *RateLimitTestSuite) TestMiddlewareUsesReplacedLimits() {
+	suite.track("")
+	suite.track("")
+	suite.Equal(http.StatusTooManyRequests, suite.track("").Code)
+
+	suite.limits.Replace(map[string]Limit{"/track": {Rate: 1, Burst: 10}})
+	suite.now = suite.now.Add(time.Second)
+	recorder := suite.track("")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("10", recorder.Header().Get(constants.RateLimitLimit))
+}
+
+func (suite *RateLimitTestSuite) TestLimitsFallBackForUnknownRoutes() {
+	suite.Equal(Limit{Rate: 1, Burst: 1}, suite.limits.Get("/track/batch"))
+}
+
+func (suite *RateLimitTestSuite) TestPeekDoesNotTakeTokens() {
+	limit := Limit{Rate: 1, Burst: 1}
+
+	suite.True(suite.store.Peek("client", limit).Allowed)
+	suite.NotContains(suite.store.buckets, "client")
+	suite.True(suite.store.Take("client", limit).Allowed)
+	suite.False(suite.store.Peek("client", limit).Allowed)
+}
+
+func (suite *RateLimitTestSuite) TestAuthFailureMiddlewareLimitsFailedAttempts() {
+	router := gin.New()
+	router.POST("/track", AuthFailureMiddleware(suite.store, suite.limits, "/track"), func(c *gin.Context) {
+		if c.GetHeader(constants.APIKeyHeader) != "valid" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	attempt := func(apiKey string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/track", nil)
+		req.Header.Set(constants.APIKeyHeader, apiKey)
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	//successful requests are not charged
+	for i := 0; i < 5; i++ {
+		suite.Equal(http.StatusOK, attempt("valid").Code)
+	}
+	suite.Equal(http.StatusUnauthorized, attempt("guess-1").Code)
+	suite.Equal(http.StatusUnauthorized, attempt("guess-2").Code)
+
+	recorder := attempt("guess-3")
+	suite.Equal(http.StatusTooManyRequests, recorder.Code)
+	suite.Equal("1", recorder.Header().Get(constants.RetryAfter))
+	//the client IP is blocked until its failures refill, whatever the key
+	suite.Equal(http.StatusTooManyRequests, attempt("valid").Code)
+
+	suite.now = suite.now.Add(time.Second)
+	suite.Equal(http.StatusOK, attempt("valid").Code)
+}
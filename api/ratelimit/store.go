@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+//Limit describes a token bucket which refills at Rate tokens per second up to Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+//RouteLimits gives the limit of each route
+type RouteLimits interface {
+	Get(route string) Limit
+}
+
+//Limits holds the limit of each route, it can be replaced while requests use it
+type Limits struct {
+	mu       sync.RWMutex
+	limits   map[string]Limit
+	fallback Limit
+}
+
+//NewLimits returns the limits of the routes, routes without a limit get the fallback
+func NewLimits(limits map[string]Limit, fallback Limit) *Limits {
+	return &Limits{limits: limits, fallback: fallback}
+}
+
+func (l *Limits) Get(route string) Limit {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if limit, ok := l.limits[route]; ok {
+		return limit
+	}
+	return l.fallback
+}
+
+//Replace swaps every route limit in one step
+func (l *Limits) Replace(limits map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+}
+
+//Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+//Store keeps the token buckets, keyed by client and route
+type Store interface {
+	Take(key string, limit Limit) Result
+	//Peek returns the result Take would give without taking a token
+	Peek(key string, limit Limit) Result
+}
+
+//sweepInterval is how often the buckets which refilled are dropped
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+	//fullAt is when the bucket is back to its burst, from then it is the same as a new bucket and can be dropped
+	fullAt time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+//NewMemoryStore returns a Store which holds buckets in the memory of this process
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (ms *memoryStore) Take(key string, limit Limit) Result {
+	return ms.take(key, limit, true)
+}
+
+func (ms *memoryStore) Peek(key string, limit Limit) Result {
+	return ms.take(key, limit, false)
+}
+
+func (ms *memoryStore) take(key string, limit Limit, consume bool) Result {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	burst := float64(limit.Burst)
+
+	//idle clients would otherwise keep their bucket forever
+	if now.Sub(ms.lastSweep) >= sweepInterval {
+		ms.sweep(now)
+	}
+
+	b, ok := ms.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, lastSeen: now}
+		//a peek does not keep the new bucket
+		if consume {
+			ms.buckets[key] = b
+		}
+	}
+
+	//refill the bucket for the time elapsed since the last request
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.lastSeen).Seconds()*limit.Rate)
+	b.lastSeen = now
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		if consume {
+			b.tokens--
+		}
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((burst - b.tokens) / limit.Rate)
+	b.fullAt = now.Add(result.Reset)
+	return result
+}
+
+//sweep drops the buckets which are full again
+func (ms *memoryStore) sweep(now time.Time) {
+	for key, b := range ms.buckets {
+		if !now.Before(b.fullAt) {
+			delete(ms.buckets, key)
+		}
+	}
+	ms.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
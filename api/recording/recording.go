@@ -0,0 +1,193 @@
+//Package recording writes the requests served and their responses as JSON lines which the replay
+//command plays again, to find the responses which changed
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//credentials are never recorded, even when listed in the headers to record
+var neverRecorded = map[string]bool{
+	http.CanonicalHeaderKey(constants.Authorization): true,
+	http.CanonicalHeaderKey(constants.APIKeyHeader):  true,
+	"Cookie": true,
+}
+
+//ResponseHeaders are the response headers recorded, the replay compares those found in the recording
+var ResponseHeaders = []string{"Content-Type", "Content-Language"}
+
+//Exchange is a request and its response, one line of a recording
+type Exchange struct {
+	Time     time.Time `json:"time"`
+	Request  Request   `json:"request"`
+	Response Response  `json:"response"`
+}
+
+//Request is recorded without its query and credentials, its body is redacted as the logs are
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+//Recorder writes exchanges, it is safe for concurrent use
+type Recorder interface {
+	Record(exchange Exchange) error
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+//NewRecorder writes one JSON exchange per line to w
+func NewRecorder(w io.Writer) Recorder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &recorder{encoder: encoder}
+}
+
+func (r *recorder) Record(exchange Exchange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.encoder.Encode(exchange)
+}
+
+//ReadExchanges reads a recording, blank lines are skipped
+func ReadExchanges(r io.Reader) ([]Exchange, error) {
+	var exchanges []Exchange
+	decoder := json.NewDecoder(r)
+	for {
+		var exchange Exchange
+		err := decoder.Decode(&exchange)
+		if err == io.EOF {
+			return exchanges, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("exchange %d: %v", len(exchanges)+1, err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+}
+
+//maxRecordedBody is the size of the largest request or response body recorded, exchanges with a
+//larger body are served but not recorded
+const maxRecordedBody = 1 << 20
+
+//bodyWriter keeps a copy of the response body, up to maxRecordedBody
+type bodyWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyWriter) keep(data []byte) {
+	if w.truncated || w.body.Len()+len(data) > maxRecordedBody {
+		w.truncated = true
+		return
+	}
+	w.body.Write(data)
+}
+
+//RecordMiddleware records each request with its response once it is served. Only the given request
+//headers are kept, and the bodies are redacted with the log redaction. It is meant to run once the
+//client is authenticated, so anonymous clients cannot fill the recording. Requests whose body is
+//larger than maxRecordedBody, and responses not written by the handlers, are not recorded
+func RecordMiddleware(r Recorder, headers []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.GetLogger(c).
+			WithField(constants.Interface, "recording").
+			WithField(constants.Method, "RecordMiddleware")
+
+		start := time.Now()
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(io.LimitReader(c.Request.Body, maxRecordedBody+1))
+			//the handlers read the whole body whether it is recorded or not
+			c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body), Closer: c.Request.Body}
+			if err != nil || len(body) > maxRecordedBody {
+				logger.Warnf("Request body is not recorded, it could not be read or exceeds %d bytes", maxRecordedBody)
+				c.Next()
+				return
+			}
+		}
+		writer := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		//the response of a request past its deadline is written once the handlers return
+		if !writer.Written() {
+			return
+		}
+		if writer.truncated {
+			logger.Warnf("Response body is not recorded, it exceeds %d bytes", maxRecordedBody)
+			return
+		}
+		exchange := Exchange{
+			Time: start.UTC(),
+			Request: Request{
+				Method:  c.Request.Method,
+				Path:    c.Request.URL.Path,
+				Headers: recordedHeaders(c.Request.Header, headers),
+				Body:    string(logging.RedactBody(body)),
+			},
+			Response: Response{
+				Status:  writer.Status(),
+				Headers: recordedHeaders(writer.Header(), ResponseHeaders),
+				Body:    string(logging.RedactBody(writer.body.Bytes())),
+			},
+		}
+		if err := r.Record(exchange); err != nil {
+			logger.Errorf("Record - %s", err.Error())
+		}
+	}
+}
+
+//readCloser reads the request body again while closing the original one
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func recordedHeaders(header http.Header, names []string) map[string]string {
+	recorded := make(map[string]string)
+	for _, name := range names {
+		if value := header.Get(name); value != "" && !neverRecorded[http.CanonicalHeaderKey(name)] {
+			recorded[name] = value
+		}
+	}
+	if len(recorded) == 0 {
+		return nil
+	}
+	return recorded
+}
@@ -0,0 +1,111 @@
+package recording
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecordingTestSuite struct {
+	suite.Suite
+	output *bytes.Buffer
+	router *gin.Engine
+}
+
+func TestRecording(t *testing.T) {
+	suite.Run(t, new(RecordingTestSuite))
+}
+
+func (suite *RecordingTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.output = new(bytes.Buffer)
+	suite.router = gin.New()
+	suite.router.POST("/track", RecordMiddleware(NewRecorder(suite.output), []string{"Content-Type", constants.TenantHeader, constants.APIKeyHeader}), func(c *gin.Context) {
+		body := new(struct {
+			Tickets [][]string `json:"tickets"`
+		})
+		if err := c.ShouldBindJSON(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error_code": "ERR_API_BAD_REQUEST"})
+			return
+		}
+		c.JSON(http.StatusOK, []string{body.Tickets[0][0], body.Tickets[0][1]})
+	})
+}
+
+func (suite *RecordingTestSuite) TestRecordsSanitizedExchanges() {
+	req := httptest.NewRequest(http.MethodPost, "/track?api_key=secret", strings.NewReader(`{"tickets": [["SFO", "EWR"]]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constants.TenantHeader, "acme")
+	req.Header.Set(constants.APIKeyHeader, "secret")
+	req.Header.Set("User-Agent", "test")
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, req)
+
+	//the handler still reads the body
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.NotContains(suite.output.String(), "secret")
+
+	exchanges, err := ReadExchanges(suite.output)
+	suite.Require().Nil(err)
+	suite.Require().Len(exchanges, 1)
+	exchange := exchanges[0]
+	suite.Equal(Request{
+		Method:  http.MethodPost,
+		Path:    "/track",
+		Headers: map[string]string{"Content-Type": "application/json", constants.TenantHeader: "acme"},
+		Body:    `{"tickets": [["SFO", "EWR"]]}`,
+	}, exchange.Request)
+	suite.Equal(Response{
+		Status:  http.StatusOK,
+		Headers: map[string]string{"Content-Type": "application/json; charset=utf-8"},
+		Body:    `["SFO","EWR"]`,
+	}, exchange.Response)
+	suite.False(exchange.Time.IsZero())
+}
+
+func (suite *RecordingTestSuite) TestRecordsInvalidBodies() {
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/track", strings.NewReader("not json")))
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/track", strings.NewReader(`{"tickets": [["IND", "EWR"]]}`)))
+
+	exchanges, err := ReadExchanges(suite.output)
+	suite.Require().Nil(err)
+	suite.Require().Len(exchanges, 2)
+	suite.Equal("not json", exchanges[0].Request.Body)
+	suite.Equal(http.StatusBadRequest, exchanges[0].Response.Status)
+	suite.Equal(`["IND","EWR"]`, exchanges[1].Response.Body)
+}
+
+func (suite *RecordingTestSuite) TestLargeBodiesAreServedButNotRecorded() {
+	tickets := strings.Repeat(`["IND", "EWR"], `, maxRecordedBody/14)
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/track", strings.NewReader(`{"tickets": [`+tickets+`["SFO", "ATL"]]}`)))
+
+	//the handler still reads the whole body
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal(`["IND","EWR"]`, recorder.Body.String())
+	suite.Empty(suite.output.String())
+}
+
+func (suite *RecordingTestSuite) TestUnwrittenResponsesAreNotRecorded() {
+	router := gin.New()
+	router.POST("/track", func(c *gin.Context) {
+		c.Next()
+		//written after the recording middleware returns, as the timeout middleware does
+		c.Status(http.StatusGatewayTimeout)
+	}, RecordMiddleware(NewRecorder(suite.output), nil), func(c *gin.Context) {})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/track", strings.NewReader(`{}`)))
+
+	suite.Empty(suite.output.String())
+}
+
+func (suite *RecordingTestSuite) TestReadExchangesRejectsInvalidLines() {
+	_, err := ReadExchanges(strings.NewReader("{\"request\": {}}\nnot json\n"))
+	suite.NotNil(err)
+	suite.Contains(err.Error(), "exchange 2")
+}
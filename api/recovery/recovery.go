@@ -0,0 +1,47 @@
+package recovery
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tracing"
+	"go.opencensus.io/trace"
+)
+
+//RecoveryMiddleware turns a panic of the rest of the chain into an ERR_API_INTERNAL error
+//response. The panic and its stack are logged with the request logger, which also annotates
+//them on the request span. http.ErrAbortHandler is panicked again so net/http aborts the response
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			//http.ErrAbortHandler asks net/http to abort the connection without logging, it is not an error
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			logger := logging.GetLogger(c).
+				WithField(constants.Interface, "RecoveryMiddleware").
+				WithField(constants.Method, c.Request.Method+" "+c.FullPath())
+			logger.Errorf("Recovered from panic - %v\n%s", recovered, debug.Stack())
+
+			if span := trace.FromContext(c.Request.Context()); span != nil {
+				tracing.SetErrorStatus(span, errors.ErrInternal)
+			}
+			//a partly written response can not be replaced, the request is only stopped
+			if c.Writer.Written() {
+				c.Set(constants.ERROR_CODE_KEY, errors.ErrInternal.ErrorCode)
+				c.Abort()
+				return
+			}
+			errors.AbortWithErrorResponse(c, errors.ErrInternal)
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,89 @@
+package recovery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecoveryTestSuite struct {
+	suite.Suite
+	hook   *test.Hook
+	router *gin.Engine
+}
+
+func TestRecovery(t *testing.T) {
+	suite.Run(t, new(RecoveryTestSuite))
+}
+
+func (suite *RecoveryTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+	suite.hook = test.NewLocal(logrus.StandardLogger())
+}
+
+func (suite *RecoveryTestSuite) SetupTest() {
+	suite.hook.Reset()
+	suite.router = gin.New()
+	suite.router.Use(requestid.New())
+	suite.router.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()))
+	suite.router.Use(RecoveryMiddleware())
+	suite.router.POST("/track", func(c *gin.Context) {
+		var tickets [][]string
+		c.JSON(http.StatusOK, tickets[0][1])
+	})
+	suite.router.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	suite.router.GET("/partial", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("after write")
+	})
+}
+
+func (suite *RecoveryTestSuite) TestPanicReturnsInternalError() {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/track", nil)
+	req.Header.Set("X-Request-ID", "42")
+	suite.router.ServeHTTP(recorder, req)
+
+	suite.Equal(http.StatusInternalServerError, recorder.Code)
+	var response errors.ErrorResponse
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Equal(errors.ErrorCode(errors.Internal), response.ErrorCode)
+
+	entry := suite.hook.LastEntry()
+	suite.Require().NotNil(entry)
+	suite.Equal(logrus.ErrorLevel, entry.Level)
+	suite.Equal("42", entry.Data[constants.ReqID])
+	suite.Contains(entry.Message, "index out of range")
+	suite.Contains(entry.Message, "runtime/debug.Stack")
+}
+
+func (suite *RecoveryTestSuite) TestPanicAfterWriteKeepsResponse() {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/partial", nil)
+	suite.router.ServeHTTP(recorder, req)
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("partial", recorder.Body.String())
+	suite.Contains(suite.hook.LastEntry().Message, "after write")
+}
+
+func (suite *RecoveryTestSuite) TestAbortHandlerIsPanickedAgain() {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/abort", nil)
+
+	suite.PanicsWithValue(http.ErrAbortHandler, func() { suite.router.ServeHTTP(recorder, req) })
+	suite.Empty(recorder.Body.String())
+	suite.Nil(suite.hook.LastEntry())
+}
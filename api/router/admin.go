@@ -0,0 +1,57 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/accesslog"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/admin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/auth"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/config"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/ratelimit"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/recovery"
+)
+
+//AdminLogRoute is the route of the log settings, its required scopes are configured in the auth config
+const AdminLogRoute = "/admin/log"
+
+//SetupAdminRouter serves the admin API, which is meant to listen on a private address. Every admin route
+//is authenticated, so an error is returned unless an auth config file gives the scopes of the admin routes
+func SetupAdminRouter(cfg *config.Config, logSettings admin.LogSettings, reloadables *Reloadables) (*gin.Engine, error) {
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("could not set trusted proxies of the admin API: %v", err)
+	}
+
+	authenticator, err := loadAuthenticator(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not load auth config of the admin API: %v", err)
+	}
+	if authenticator == nil {
+		return nil, fmt.Errorf("admin API requires auth, auth.config_file is not set")
+	}
+	//without scopes any principal of the tracking endpoints would pass, so admin calls are denied by default
+	if len(authenticator.RequiredScopes(AdminLogRoute)) == 0 {
+		return nil, fmt.Errorf("admin API requires the auth config to give scopes for %s", AdminLogRoute)
+	}
+
+	apiLoggerEntry := logging.NewLoggerEntry()
+	router.Use(requestid.New())
+	router.Use(logging.LoggingMiddleware(apiLoggerEntry))
+	router.Use(accesslog.AccessLogMiddleware(accessLogOptions(cfg)))
+	router.Use(recovery.RecoveryMiddleware())
+
+	adminController := admin.NewAdminController(logSettings)
+
+	//failed authentications are limited with the rate limit of the route, as on the tracking routes
+	logRoutes := router.Group(AdminLogRoute,
+		ratelimit.AuthFailureMiddleware(ratelimit.NewMemoryStore(), reloadableRateLimits{reloadables}, AdminLogRoute),
+		auth.AuthMiddleware(authenticator, AdminLogRoute))
+	logRoutes.GET("", adminController.GetLogSettings)
+	logRoutes.PUT("", adminController.UpdateLogSettings)
+	logRoutes.DELETE("", adminController.ResetLogSettings)
+
+	return router, nil
+}
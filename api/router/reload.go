@@ -0,0 +1,139 @@
+package router
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/airports"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/config"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/ratelimit"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/timeout"
+)
+
+//Reloadables are the parts of the router which can be replaced while it serves requests. They are
+//loaded together into one snapshot which is swapped in a single step, so requests never see the
+//settings of two configs and a failed reload changes nothing
+type Reloadables struct {
+	current atomic.Value
+	//loadErr holds the error of the last failed airports load, until a load succeeds
+	loadErr atomic.Value
+}
+
+//loadResult keeps the concrete type stored in the atomic.Value the same
+type loadResult struct {
+	err error
+}
+
+//snapshot holds the reloadable settings of one config
+type snapshot struct {
+	airports   *airports.TenantRegistries
+	rateLimits *ratelimit.Limits
+	timeouts   *timeout.Timeouts
+}
+
+//newReloadables loads the airports, rate limits and timeouts of the config
+func newReloadables(cfg *config.Config) (*Reloadables, error) {
+	r := &Reloadables{}
+	if err := r.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//Reload applies the airports, rate limits and timeouts of the config. Everything is loaded before
+//anything is replaced, so on error the router keeps running with its previous settings
+func (r *Reloadables) Reload(cfg *config.Config) error {
+	airportRegistry, tenantRegistries, err := loadAirports(cfg)
+	r.loadErr.Store(loadResult{err})
+	if err != nil {
+		return err
+	}
+	r.current.Store(&snapshot{
+		airports:   airports.NewTenantRegistries(airportRegistry, tenantRegistries),
+		rateLimits: ratelimit.NewLimits(rateLimits(cfg), defaultRateLimit()),
+		timeouts:   timeout.NewTimeouts(timeouts(cfg), defaultTimeout()),
+	})
+	return nil
+}
+
+//AirportsCheck is the readiness check of the airports, it fails while the last reload of the airports
+//files failed and the server still uses the airports of an earlier config
+func (r *Reloadables) AirportsCheck() error {
+	if err := r.loadErr.Load().(loadResult).err; err != nil {
+		return fmt.Errorf("airports reload failed, serving the previous airports: %v", err)
+	}
+	return nil
+}
+
+func (r *Reloadables) snapshot() *snapshot {
+	return r.current.Load().(*snapshot)
+}
+
+//reloadableAirports, reloadableRateLimits and reloadableTimeouts read the current snapshot
+type reloadableAirports struct{ *Reloadables }
+
+type reloadableRateLimits struct{ *Reloadables }
+
+type reloadableTimeouts struct{ *Reloadables }
+
+func (r reloadableAirports) Get(tenantID tenant.ID) airports.Registry {
+	return r.snapshot().airports.Get(tenantID)
+}
+
+func (r reloadableRateLimits) Get(route string) ratelimit.Limit {
+	return r.snapshot().rateLimits.Get(route)
+}
+
+func (r reloadableTimeouts) Get(route string) time.Duration {
+	return r.snapshot().timeouts.Get(route)
+}
+
+//loadAirports restricts airport codes to the airports file when one is given, and the codes of
+//each tenant with a file of its own to that file
+func loadAirports(cfg *config.Config) (airports.Registry, map[tenant.ID]airports.Registry, error) {
+	tenantRegistries := make(map[tenant.ID]airports.Registry, len(cfg.Airports.TenantFiles))
+	for tenantID, file := range cfg.Airports.TenantFiles {
+		registry, err := airports.LoadRegistry(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		tenantRegistries[tenantID] = registry
+	}
+	if cfg.Airports.File == "" {
+		return airports.NewRegistry(), tenantRegistries, nil
+	}
+	airportRegistry, err := airports.LoadRegistry(cfg.Airports.File)
+	if err != nil {
+		return nil, nil, err
+	}
+	return airportRegistry, tenantRegistries, nil
+}
+
+func rateLimits(cfg *config.Config) map[string]ratelimit.Limit {
+	limits := make(map[string]ratelimit.Limit, len(cfg.RateLimits))
+	for route, limit := range cfg.RateLimits {
+		limits[route] = ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+	return limits
+}
+
+func timeouts(cfg *config.Config) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(cfg.Timeouts))
+	for route, timeout := range cfg.Timeouts {
+		timeouts[route] = timeout.Duration()
+	}
+	return timeouts
+}
+
+//defaultTimeout applies to routes without a configured timeout
+func defaultTimeout() time.Duration {
+	return config.Default().Timeouts["/track"].Duration()
+}
+
+//defaultRateLimit applies to routes without a configured limit
+func defaultRateLimit() ratelimit.Limit {
+	limit := config.Default().RateLimits["/track"]
+	return ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+}
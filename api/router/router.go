@@ -2,27 +2,59 @@ package router
 
 import (
 	"net/http"
+	"os"
 
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/accesslog"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/airports"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/auth"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/config"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/controller"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/health"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/metrics"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/ratelimit"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/recording"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/recovery"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/service"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/timeout"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tlsconfig"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tracing"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/docs"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter() *gin.Engine {
-	//Get default router from gin
-	router := gin.Default()
+func SetupRouter(cfg *config.Config, apiHealth *health.Health) (*gin.Engine, *Reloadables) {
+	//gin's own access log and recovery are replaced by the structured ones below
+	router := gin.New()
 
 	//create a global logger for the server
 	apiLoggerEntry := logging.NewLoggerEntry()
 
+	//the client IP is taken from X-Forwarded-For only behind a trusted proxy, so clients cannot pick
+	//the IP their rate limits are kept under
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		apiLoggerEntry.Fatalf("Could not set trusted proxies - %s", err.Error())
+	}
+
 	//router use the global logger
-	router.Use(logging.LoggingMiddleware(apiLoggerEntry))
 	router.Use(requestid.New())
+	router.Use(logging.LoggingMiddleware(apiLoggerEntry))
+	router.Use(accesslog.AccessLogMiddleware(accessLogOptions(cfg)))
+	router.Use(tlsconfig.ClientCertMiddleware())
+	router.Use(tracing.TracingMiddleware())
+
+	//metrics of every request, served in the Prometheus format
+	apiMetrics := metrics.NewMetrics()
+	router.Use(metrics.MetricsMiddleware(apiMetrics))
+
+	//panics are recovered inside the access log, tracing and metrics middlewares so they record the error
+	router.Use(recovery.RecoveryMiddleware())
+
+	router.GET("/metrics", apiMetrics.Handler())
 
 	//swagger init
 	docs.SwaggerInfo.Title = "FLIGHT PATHS TRACKER API"
@@ -36,11 +68,91 @@ func SetupRouter() *gin.Engine {
 		c.JSON(http.StatusOK, gin.H{"status": "up"})
 	})
 
-	trackService := service.NewFlightTrackerService()
+	//liveness and readiness probes
+	router.GET("/healthz/live", apiHealth.LiveHandler)
+	router.GET("/healthz/ready", apiHealth.ReadyHandler)
+
+	//airport codes are restricted to the airports file when one is given, or to the file of the tenant
+	reloadables, err := newReloadables(cfg)
+	if err != nil {
+		apiLoggerEntry.Fatalf("Could not load airports - %s", err.Error())
+	}
+	//a failed load stops the server, a failed reload keeps the previous airports and fails the check
+	apiHealth.AddCheck(airports.Component, reloadables.AirportsCheck)
+
+	trackService := service.NewFlightTrackerService(reloadableAirports{reloadables}, apiMetrics)
 	trackController := controller.NewFlightTrackerController(trackService)
 
+	//in memory rate limit buckets shared by all the routes
+	rateLimitStore := ratelimit.NewMemoryStore()
+
+	//authentication is enabled when an auth config file is given
+	authenticator, err := loadAuthenticator(cfg)
+	if err != nil {
+		apiLoggerEntry.Fatalf("Could not load auth config - %s", err.Error())
+	}
+	if authenticator == nil {
+		apiLoggerEntry.Warn("auth.config_file is not set, tracking endpoints are not authenticated")
+	}
+
+	//requests and responses are recorded for the replay command when a record file is given
+	recorder, err := loadRecorder(cfg)
+	if err != nil {
+		apiLoggerEntry.Fatalf("Could not open record file - %s", err.Error())
+	}
+	var recordMiddleware gin.HandlerFunc
+	if recorder != nil {
+		recordMiddleware = recording.RecordMiddleware(recorder, cfg.Record.Headers)
+	}
+
 	//route to fetch source and destination from tickets
-	router.POST("/track", trackController.FindSourceAndDestination)
+	router.POST("/track", routeMiddlewares(authenticator, rateLimitStore, reloadables, recordMiddleware, cfg.Tenants.Allowed, "/track", trackController.FindSourceAndDestination)...)
+
+	return router, reloadables
+}
+
+//routeMiddlewares chains the deadline, failed authentication limit, authentication, tenant resolution, rate
+//limiting and recording in front of the handler of a route. Only the requests which reach the handler are recorded
+func routeMiddlewares(authenticator auth.Authenticator, rateLimitStore ratelimit.Store, reloadables *Reloadables, recordMiddleware gin.HandlerFunc, allowedTenants []string, route string, handler gin.HandlerFunc) []gin.HandlerFunc {
+	handlers := []gin.HandlerFunc{timeout.TimeoutMiddleware(reloadableTimeouts{reloadables}, route)}
+	if authenticator != nil {
+		handlers = append(handlers, ratelimit.AuthFailureMiddleware(rateLimitStore, reloadableRateLimits{reloadables}, route), auth.AuthMiddleware(authenticator, route))
+	}
+	handlers = append(handlers, tenant.TenantMiddleware(allowedTenants), ratelimit.RateLimitMiddleware(rateLimitStore, reloadableRateLimits{reloadables}, route))
+	if recordMiddleware != nil {
+		handlers = append(handlers, recordMiddleware)
+	}
+	return append(handlers, handler)
+}
+
+//loadAuthenticator returns no authenticator when no auth config file is given
+func loadAuthenticator(cfg *config.Config) (auth.Authenticator, error) {
+	if cfg.Auth.ConfigFile == "" {
+		return nil, nil
+	}
+	authConfig, jwks, err := auth.LoadConfig(cfg.Auth.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewAuthenticator(authConfig, jwks), nil
+}
+
+//loadRecorder returns no recorder when no record file is given, recordings are appended to the file
+func loadRecorder(cfg *config.Config) (recording.Recorder, error) {
+	if cfg.Record.File == "" {
+		return nil, nil
+	}
+	file, err := os.OpenFile(cfg.Record.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return recording.NewRecorder(file), nil
+}
 
-	return router
+func accessLogOptions(cfg *config.Config) accesslog.Options {
+	return accesslog.Options{
+		Headers:       cfg.AccessLog.Headers,
+		RedactHeaders: cfg.AccessLog.RedactHeaders,
+		RedactQuery:   cfg.AccessLog.RedactQuery,
+	}
 }
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/admin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/config"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/health"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/stretchr/testify/suite"
+)
+
+type RouterTestSuite struct {
+	suite.Suite
+	cfg *config.Config
+}
+
+func TestRouter(t *testing.T) {
+	suite.Run(t, new(RouterTestSuite))
+}
+
+func (suite *RouterTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.Require().Nil(logging.NewLoggerEntry().SetLevel(logging.PANIC))
+	suite.cfg = config.Default()
+	suite.cfg.RateLimits = map[string]config.RateLimitConfig{"/track": {Rate: 0.001, Burst: 1}}
+}
+
+//trackFrom sends a tracking request from the remote address with the X-Forwarded-For header
+func (suite *RouterTestSuite) trackFrom(router *gin.Engine, remoteAddr string, forwardedFor string) int {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/track", strings.NewReader(`{"tickets": [["SFO", "EWR"]]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	req.RemoteAddr = remoteAddr
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func (suite *RouterTestSuite) TestForwardedForIsIgnoredWithoutTrustedProxies() {
+	router, _ := SetupRouter(suite.cfg, health.NewHealth())
+
+	suite.Equal(http.StatusOK, suite.trackFrom(router, "203.0.113.7:4000", "198.51.100.1"))
+	//a new X-Forwarded-For on each request does not open a new rate limit bucket
+	suite.Equal(http.StatusTooManyRequests, suite.trackFrom(router, "203.0.113.7:4000", "198.51.100.2"))
+	suite.Equal(http.StatusTooManyRequests, suite.trackFrom(router, "203.0.113.7:4001", "198.51.100.3"))
+}
+
+func (suite *RouterTestSuite) TestForwardedForOfTrustedProxiesIsUsed() {
+	suite.cfg.Server.TrustedProxies = []string{"203.0.113.0/24"}
+	router, _ := SetupRouter(suite.cfg, health.NewHealth())
+
+	suite.Equal(http.StatusOK, suite.trackFrom(router, "203.0.113.7:4000", "198.51.100.1"))
+	suite.Equal(http.StatusOK, suite.trackFrom(router, "203.0.113.7:4000", "198.51.100.2"))
+	suite.Equal(http.StatusTooManyRequests, suite.trackFrom(router, "203.0.113.7:4000", "198.51.100.2"))
+}
+
+func (suite *RouterTestSuite) TestAdminRoutesLimitFailedAuthentications() {
+	authFile := filepath.Join(suite.T().TempDir(), "auth.json")
+	suite.Require().Nil(ioutil.WriteFile(authFile, []byte(`{
+		"api_keys": [{"key": "admin-key", "subject": "ops", "scopes": ["admin"]}],
+		"scopes": {"/admin/log": ["admin"]}
+	}`), 0600))
+	suite.cfg.Auth.ConfigFile = authFile
+	suite.cfg.RateLimits[AdminLogRoute] = config.RateLimitConfig{Rate: 0.001, Burst: 1}
+	_, reloadables := SetupRouter(suite.cfg, health.NewHealth())
+	logSettings, err := admin.NewLogSettings(logging.NewLoggerEntry(), logging.PANIC, constants.TEXT)
+	suite.Require().Nil(err)
+	router, err := SetupAdminRouter(suite.cfg, logSettings, reloadables)
+	suite.Require().Nil(err)
+
+	getLog := func(apiKey string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, AdminLogRoute, nil)
+		req.Header.Set(constants.APIKeyHeader, apiKey)
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	suite.Equal(http.StatusOK, getLog("admin-key"))
+	suite.Equal(http.StatusUnauthorized, getLog("guess-1"))
+	suite.Equal(http.StatusTooManyRequests, getLog("guess-2"))
+}
+
+func (suite *RouterTestSuite) TestAdminRouterRequiresAuth() {
+	_, reloadables := SetupRouter(suite.cfg, health.NewHealth())
+	logSettings, err := admin.NewLogSettings(logging.NewLoggerEntry(), logging.PANIC, constants.TEXT)
+	suite.Require().Nil(err)
+
+	_, err = SetupAdminRouter(suite.cfg, logSettings, reloadables)
+	suite.NotNil(err)
+	suite.Contains(err.Error(), "admin API requires auth")
+
+	authFile := filepath.Join(suite.T().TempDir(), "auth.json")
+	suite.Require().Nil(ioutil.WriteFile(authFile, []byte(`{"api_keys": [{"key": "key", "subject": "ops"}]}`), 0600))
+	suite.cfg.Auth.ConfigFile = authFile
+	_, err = SetupAdminRouter(suite.cfg, logSettings, reloadables)
+	suite.NotNil(err)
+	suite.Contains(err.Error(), AdminLogRoute)
+}
+
+func (suite *RouterTestSuite) TestReloadSwapsAllSettingsOrNone() {
+	reloadables, err := newReloadables(suite.cfg)
+	suite.Require().Nil(err)
+
+	next := config.Default()
+	next.RateLimits = map[string]config.RateLimitConfig{"/track": {Rate: 1, Burst: 5}}
+	next.Timeouts = map[string]config.Duration{"/track": config.Duration(time.Second)}
+	next.Airports.File = filepath.Join(suite.T().TempDir(), "missing.txt")
+	suite.Nil(reloadables.AirportsCheck())
+	suite.NotNil(reloadables.Reload(next))
+	suite.NotNil(reloadables.AirportsCheck())
+	suite.Equal(1, reloadableRateLimits{reloadables}.Get("/track").Burst)
+	suite.Equal(10*time.Second, reloadableTimeouts{reloadables}.Get("/track"))
+	suite.False(reloadableAirports{reloadables}.Get(tenant.Default).Restricted())
+
+	suite.Require().Nil(ioutil.WriteFile(next.Airports.File, []byte("SFO\n"), 0600))
+	suite.Nil(reloadables.Reload(next))
+	suite.Nil(reloadables.AirportsCheck())
+	suite.Equal(5, reloadableRateLimits{reloadables}.Get("/track").Burst)
+	suite.Equal(time.Second, reloadableTimeouts{reloadables}.Get("/track"))
+	suite.True(reloadableAirports{reloadables}.Get(tenant.Default).Restricted())
+}
@@ -1,13 +1,20 @@
 package service
 
 import (
-	"strings"
+	"context"
+	stderrors "errors"
+	"sort"
 
-	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/airports"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/metrics"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tracing"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
+	"go.opencensus.io/trace"
 )
 
 type FlightTrackerService interface {
@@ -15,74 +22,133 @@ type FlightTrackerService interface {
 	ValidateTickets(c *gin.Context, tickets [][]string) *errors.ErrorResponse
 }
 
+//flightTrackerService adapts the flightpath library to the API, with its spans, logs, metrics and error responses
 type flightTrackerService struct {
+	airports airports.Registries
+	metrics  *metrics.Metrics
 }
 
-func NewFlightTrackerService() FlightTrackerService {
-	return &flightTrackerService{}
+func NewFlightTrackerService(airportRegistries airports.Registries, serviceMetrics *metrics.Metrics) FlightTrackerService {
+	return &flightTrackerService{
+		airports: airportRegistries,
+		metrics:  serviceMetrics,
+	}
+}
+
+//tracker checks the airport codes against the registry of the tenant of the request
+func (fts *flightTrackerService) tracker(c *gin.Context) flightpath.Tracker {
+	return flightpath.NewTracker(fts.airports.Get(tenant.Get(c)))
 }
 
 func (fts *flightTrackerService) FindSourceAndDestination(c *gin.Context, tickets [][]string) ([]string, *errors.ErrorResponse) {
+	ctx, span := tracing.StartSpan(c, "FlightTrackerService.FindSourceAndDestination")
+	defer span.End()
+	span.AddAttributes(trace.Int64Attribute(tracing.TicketsAttribute, int64(len(tickets))))
+
 	logger := logging.GetLogger(c).
-		WithField(constants.ReqID, requestid.Get(c)).
+		WithContext(ctx).
 		WithField(constants.Interface, "FlightTrackerService").
 		WithField(constants.Method, "FindSourceAndDestination")
 
-	srcdst := make([]string, 2)
-	flightPath := make(map[string]int)
-
-	for _, ticket := range tickets {
-		flightPath[ticket[0]]--
-		flightPath[ticket[1]]++
+	itinerary, err := fts.tracker(c).Track(ctx, ToTickets(tickets))
+	if err != nil {
+		e := ErrorResponse(err)
+		logger.Errorf("Track - %s", err.Error())
+		tracing.SetErrorStatus(span, e)
+		return nil, e
 	}
 
-	//validate the number of source and destinations
-	for k, v := range flightPath {
-		if v > 1 || v < -1 {
-			logger.Errorf("Error invalid number of source and destinations - %s", errors.ErrUnableToTrack.Error())
-			return nil, errors.ErrUnableToTrack
-		}
-		if v == 0 {
-			delete(flightPath, k)
-		}
-	}
-	//check if there is only one source and one destination
-	if len(flightPath) != 2 {
-		logger.Errorf("Error invalid flght paths -  %s", errors.ErrUnableToTrack.Error())
-		return nil, errors.ErrUnableToTrack
-	}
-
-	//fetch the source which has value as -1 and destination which has value as 1 from the flightPath map
-	for k, v := range flightPath {
-		switch v {
-		case -1:
-			srcdst[0] = k
-		case 1:
-			srcdst[1] = k
-		}
-	}
-	return srcdst, nil
+	fts.metrics.ObserveItinerary(c, len(tickets))
+	return []string{itinerary.Source, itinerary.Destination}, nil
 }
 
 func (fts *flightTrackerService) ValidateTickets(c *gin.Context, tickets [][]string) *errors.ErrorResponse {
+	ctx, span := tracing.StartSpan(c, "FlightTrackerService.ValidateTickets")
+	defer span.End()
+	span.AddAttributes(trace.Int64Attribute(tracing.TicketsAttribute, int64(len(tickets))))
+
 	logger := logging.GetLogger(c).
-		WithField(constants.ReqID, requestid.Get(c)).
+		WithContext(ctx).
 		WithField(constants.Interface, "FlightTrackerService").
 		WithField(constants.Method, "ValidateTickets")
 
-	for _, ticket := range tickets {
+	fts.metrics.ObserveTickets(c, len(tickets))
+
+	validationErrors, err := Validate(ctx, fts.tracker(c), tickets)
+	if err != nil {
+		e := ErrorResponse(err)
+		logger.Errorf("Validate - %s", err.Error())
+		tracing.SetErrorStatus(span, e)
+		return e
+	}
+
+	if len(validationErrors) > 0 {
+		logger.Errorf("Error in %d ticket fields - %s", len(validationErrors), errors.ErrInvalidTicket.Error())
+		tracing.SetErrorStatus(span, errors.ErrInvalidTicket)
+		return errors.ErrInvalidTicket.WithErrors(validationErrors)
+	}
+	return nil
+}
+
+//Validate checks the shape of each ticket, then the library validates the airport codes. The validation
+//errors are in the order of the tickets, err is only returned when the validation could not complete
+func Validate(ctx context.Context, tracker flightpath.Tracker, tickets [][]string) ([]errors.ValidationError, error) {
+	var validationErrors []errors.ValidationError
+	var wellFormed []flightpath.Ticket
+	var indexes []int
+	for i, ticket := range tickets {
 		//check if each ticket has exactly one source and one destination
 		if len(ticket) != 2 {
-			logger.Errorf("Error ticket format - %s", errors.ErrInvalidTicket.Error())
-			return errors.ErrInvalidTicket
+			validationErrors = append(validationErrors, errors.ValidationError{Ticket: i, Reason: errors.ReasonWrongTicketSize})
+			continue
 		}
-		for _, place := range ticket {
-			//check the source and destination's naming convention
-			if strings.ToUpper(place) != place || len(place) != 3 {
-				logger.Errorf("Error in source or destination name - %s", errors.ErrInvalidTicket.Error())
-				return errors.ErrInvalidTicket
-			}
+		wellFormed = append(wellFormed, flightpath.Ticket{Origin: ticket[0], Destination: ticket[1]})
+		indexes = append(indexes, i)
+	}
+
+	err := tracker.Validate(ctx, wellFormed)
+	var invalidTickets *flightpath.InvalidTicketsError
+	switch {
+	case stderrors.As(err, &invalidTickets):
+		for _, validationError := range invalidTickets.Errors {
+			validationErrors = append(validationErrors, errors.ValidationError{
+				Ticket: indexes[validationError.Ticket],
+				Field:  validationError.Field,
+				Value:  validationError.Value,
+				Reason: string(validationError.Reason),
+			})
 		}
+		//keep the errors in the order of the tickets
+		sort.SliceStable(validationErrors, func(i, j int) bool {
+			return validationErrors[i].Ticket < validationErrors[j].Ticket
+		})
+	case err != nil:
+		return nil, err
 	}
-	return nil
+	return validationErrors, nil
+}
+
+//ToTickets converts validated tickets for the flightpath library
+func ToTickets(tickets [][]string) []flightpath.Ticket {
+	converted := make([]flightpath.Ticket, len(tickets))
+	for i, ticket := range tickets {
+		converted[i] = flightpath.Ticket{Origin: ticket[0], Destination: ticket[1]}
+	}
+	return converted
+}
+
+//ErrorResponse maps the errors of the flightpath library to the API errors
+func ErrorResponse(err error) *errors.ErrorResponse {
+	switch {
+	case stderrors.Is(err, flightpath.ErrUnableToTrack):
+		return errors.ErrUnableToTrack
+	case stderrors.Is(err, flightpath.ErrInvalidTicket):
+		return errors.ErrInvalidTicket
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return errors.ErrTimeout
+	case stderrors.Is(err, context.Canceled):
+		//the client went away, which is not counted as a timeout
+		return errors.ErrClientClosed
+	}
+	return errors.ErrInternal
 }
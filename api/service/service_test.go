@@ -1,12 +1,22 @@
 package service
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/airports"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/generator"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/metrics"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tenant"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -30,7 +40,7 @@ func (suite *FlightTrackerServiceTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.recorder = httptest.NewRecorder()
 	suite.context, _ = gin.CreateTestContext(suite.recorder)
-	suite.flightTrackerService = NewFlightTrackerService()
+	suite.flightTrackerService = NewFlightTrackerService(airports.NewTenantRegistries(airports.NewRegistry(), nil), metrics.NewMetrics())
 }
 
 func (suite *FlightTrackerServiceTestSuite) TestGetSourceAndDestinationSuccessfully() {
@@ -75,8 +85,12 @@ func (suite *FlightTrackerServiceTestSuite) TestValidateTicketsReturnsErrIfTicke
 	tickets = append(tickets, []string{"IND", "EWR", "EWR"}, []string{"IND", "EWR", "ATL"}, []string{"IND", "EWR"})
 	err := suite.flightTrackerService.ValidateTickets(suite.context, tickets)
 
-	suite.Equal(errors.ErrInvalidTicket, err)
 	suite.NotNil(err)
+	suite.Equal(errors.ErrorCode(errors.InvalidTicket), err.ErrorCode)
+	suite.Equal([]errors.ValidationError{
+		{Ticket: 0, Reason: errors.ReasonWrongTicketSize},
+		{Ticket: 1, Reason: errors.ReasonWrongTicketSize},
+	}, err.Errors)
 }
 
 func (suite *FlightTrackerServiceTestSuite) TestValidateTicketsReturnsErrIfPlaceNameIsInvalid() {
@@ -84,6 +98,105 @@ func (suite *FlightTrackerServiceTestSuite) TestValidateTicketsReturnsErrIfPlace
 	tickets = append(tickets, []string{"ind", "ewr"}, []string{"IND", "ATL"}, []string{"IND", "EWR"})
 	err := suite.flightTrackerService.ValidateTickets(suite.context, tickets)
 
-	suite.Equal(errors.ErrInvalidTicket, err)
 	suite.NotNil(err)
+	suite.Equal(errors.ErrorCode(errors.InvalidTicket), err.ErrorCode)
+	suite.Equal([]errors.ValidationError{
+		{Ticket: 0, Field: errors.FieldOrigin, Value: "ind", Reason: errors.ReasonLowercase},
+		{Ticket: 0, Field: errors.FieldDestination, Value: "ewr", Reason: errors.ReasonLowercase},
+	}, err.Errors)
+}
+
+func (suite *FlightTrackerServiceTestSuite) TestValidateTicketsReturnsEveryError() {
+	var tickets [][]string
+	tickets = append(tickets, []string{"IND", "EWR"}, []string{"AT", "SF0"}, []string{"ATL", "ATL"}, []string{"GSO"})
+	err := suite.flightTrackerService.ValidateTickets(suite.context, tickets)
+
+	suite.NotNil(err)
+	suite.Equal([]errors.ValidationError{
+		{Ticket: 1, Field: errors.FieldOrigin, Value: "AT", Reason: errors.ReasonWrongLength},
+		{Ticket: 1, Field: errors.FieldDestination, Value: "SF0", Reason: errors.ReasonNonAlpha},
+		{Ticket: 2, Field: errors.FieldDestination, Value: "ATL", Reason: errors.ReasonSelfLoop},
+		{Ticket: 3, Reason: errors.ReasonWrongTicketSize},
+	}, err.Errors)
+	suite.Nil(errors.ErrInvalidTicket.Errors)
+}
+
+func (suite *FlightTrackerServiceTestSuite) TestValidateTicketsReturnsErrIfAirportUnknown() {
+	airportsFile := filepath.Join(suite.T().TempDir(), "airports.txt")
+	suite.Require().Nil(ioutil.WriteFile(airportsFile, []byte("# known airports\nIND\nEWR\n"), 0600))
+	airportRegistry, loadErr := airports.LoadRegistry(airportsFile)
+	suite.Require().Nil(loadErr)
+
+	var tickets [][]string
+	tickets = append(tickets, []string{"IND", "EWR"}, []string{"EWR", "XYZ"})
+	err := NewFlightTrackerService(airports.NewTenantRegistries(airportRegistry, nil), metrics.NewMetrics()).ValidateTickets(suite.context, tickets)
+
+	suite.NotNil(err)
+	suite.Equal([]errors.ValidationError{
+		{Ticket: 1, Field: errors.FieldDestination, Value: "XYZ", Reason: errors.ReasonUnknownAirport},
+	}, err.Errors)
+}
+
+func (suite *FlightTrackerServiceTestSuite) TestValidateTicketsUsesAirportsOfTenant() {
+	airportsFile := filepath.Join(suite.T().TempDir(), "airports.txt")
+	suite.Require().Nil(ioutil.WriteFile(airportsFile, []byte("IND\nEWR\n"), 0600))
+	airportRegistry, loadErr := airports.LoadRegistry(airportsFile)
+	suite.Require().Nil(loadErr)
+	trackService := NewFlightTrackerService(airports.NewTenantRegistries(airports.NewRegistry(), map[tenant.ID]airports.Registry{"cargo": airportRegistry}), metrics.NewMetrics())
+
+	var tickets [][]string
+	tickets = append(tickets, []string{"IND", "EWR"}, []string{"EWR", "XYZ"})
+	suite.Nil(trackService.ValidateTickets(suite.context, tickets))
+
+	suite.context.Set(constants.TENANT_KEY, tenant.ID("cargo"))
+	err := trackService.ValidateTickets(suite.context, tickets)
+	suite.NotNil(err)
+	suite.Equal([]errors.ValidationError{
+		{Ticket: 1, Field: errors.FieldDestination, Value: "XYZ", Reason: errors.ReasonUnknownAirport},
+	}, err.Errors)
+}
+
+func (suite *FlightTrackerServiceTestSuite) TestServiceStopsWhenDeadlineExceeded() {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	suite.context.Request = httptest.NewRequest(http.MethodPost, "/track", nil).WithContext(ctx)
+	var tickets [][]string
+	tickets = append(tickets, []string{"IND", "EWR"}, []string{"SFO", "IND"})
+
+	suite.Equal(errors.ErrTimeout, suite.flightTrackerService.ValidateTickets(suite.context, tickets))
+	_, err := suite.flightTrackerService.FindSourceAndDestination(suite.context, tickets)
+	suite.Equal(errors.ErrTimeout, err)
+}
+
+func (suite *FlightTrackerServiceTestSuite) TestServiceStopsWhenClientCloses() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	suite.context.Request = httptest.NewRequest(http.MethodPost, "/track", nil).WithContext(ctx)
+	var tickets [][]string
+	tickets = append(tickets, []string{"IND", "EWR"}, []string{"SFO", "IND"})
+
+	suite.Equal(errors.ErrClientClosed, suite.flightTrackerService.ValidateTickets(suite.context, tickets))
+	_, err := suite.flightTrackerService.FindSourceAndDestination(suite.context, tickets)
+	suite.Equal(errors.ErrClientClosed, err)
+}
+
+func (suite *FlightTrackerServiceTestSuite) TestGeneratedFixtures() {
+	fixtureGenerator := generator.NewGenerator(42)
+	for i := 0; i < 20; i++ {
+		for _, kind := range generator.Kinds {
+			fixture, genErr := fixtureGenerator.Generate(kind, 3+i)
+			suite.Require().Nil(genErr)
+
+			err := suite.flightTrackerService.ValidateTickets(suite.context, fixture.Tickets)
+			if err == nil {
+				var srcdst []string
+				srcdst, err = suite.flightTrackerService.FindSourceAndDestination(suite.context, fixture.Tickets)
+				if err == nil {
+					suite.Equal(fixture.Answer(), srcdst, "%+v", fixture)
+					continue
+				}
+			}
+			suite.Equal(fixture.Error, err, "%+v", fixture)
+		}
+	}
 }
@@ -0,0 +1,80 @@
+package tenant
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/auth"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//ID identifies a tenant, any data kept for a tenant must be keyed by its ID
+type ID string
+
+//Default is the tenant of requests which do not name one
+const Default ID = "default"
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+//Valid reports whether id may be used as a tenant ID
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+//TenantMiddleware resolves the tenant of the request. The tenant of an authenticated principal
+//always wins and the X-Tenant-ID header may only repeat it. Without a principal the header is
+//trusted only for the allowed tenants, any other tenant is served as the default one so that
+//clients cannot mint tenants of their own in rate limit keys and metric labels
+func TenantMiddleware(allowed []string) gin.HandlerFunc {
+	allowedIDs := make(map[ID]bool, len(allowed))
+	for _, id := range allowed {
+		allowedIDs[ID(id)] = true
+	}
+	return func(c *gin.Context) {
+		logger := logging.GetLogger(c).
+			WithField(constants.Interface, "TenantMiddleware").
+			WithField(constants.Method, c.FullPath())
+
+		header := c.GetHeader(constants.TenantHeader)
+		if header != "" && !Valid(header) {
+			logger.Errorf("Invalid %s header - %s", constants.TenantHeader, errors.ErrInvalidTenant.Error())
+			errors.AbortWithErrorResponse(c, errors.ErrInvalidTenant)
+			return
+		}
+
+		tenantID := ID(header)
+		if principal, ok := auth.GetPrincipal(c); ok {
+			tenantID = ID(principal.Tenant)
+			if tenantID == "" {
+				tenantID = Default
+			}
+			if header != "" && ID(header) != tenantID {
+				logger.Warnf("Principal %s requested tenant %s - %s", principal.Subject, header, errors.ErrForbidden.Error())
+				errors.AbortWithErrorResponse(c, errors.ErrForbidden)
+				return
+			}
+		} else if tenantID != "" && !allowedIDs[tenantID] {
+			logger.Warnf("Tenant %s is not allowed without authentication, serving the %s tenant", header, Default)
+			tenantID = Default
+		}
+		if tenantID == "" {
+			tenantID = Default
+		}
+
+		c.Set(constants.TENANT_KEY, tenantID)
+		c.Set(constants.LOGGER_KEY, logging.GetLogger(c).WithField(constants.Tenant, string(tenantID)))
+		c.Next()
+	}
+}
+
+//Get returns the tenant resolved by TenantMiddleware or the default tenant
+func Get(c *gin.Context) ID {
+	if tenantID, ok := c.Get(constants.TENANT_KEY); ok {
+		if id, ok := tenantID.(ID); ok {
+			return id
+		}
+	}
+	return Default
+}
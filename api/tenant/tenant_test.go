@@ -0,0 +1,93 @@
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/auth"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/stretchr/testify/suite"
+)
+
+type TenantTestSuite struct {
+	suite.Suite
+	principal  *auth.Principal
+	tenantID   ID
+	loggerData logging.ApiLoggerFields
+}
+
+func TestTenant(t *testing.T) {
+	suite.Run(t, new(TenantTestSuite))
+}
+
+func (suite *TenantTestSuite) SetupTest() {
+	suite.principal = nil
+	suite.tenantID = ""
+	suite.loggerData = nil
+}
+
+func (suite *TenantTestSuite) track(header string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()))
+	router.POST("/track", func(c *gin.Context) {
+		if suite.principal != nil {
+			c.Set(constants.PRINCIPAL_KEY, suite.principal)
+		}
+	}, TenantMiddleware([]string{"cargo"}), func(c *gin.Context) {
+		suite.tenantID = Get(c)
+		suite.loggerData = logging.GetLogger(c).Data
+		c.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/track", nil)
+	if header != "" {
+		req.Header.Set(constants.TenantHeader, header)
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *TenantTestSuite) TestDefaultTenantWithoutHeader() {
+	suite.Equal(http.StatusOK, suite.track("").Code)
+	suite.Equal(Default, suite.tenantID)
+}
+
+func (suite *TenantTestSuite) TestTenantFromHeader() {
+	suite.Equal(http.StatusOK, suite.track("cargo").Code)
+	suite.Equal(ID("cargo"), suite.tenantID)
+	suite.Equal("cargo", suite.loggerData[constants.Tenant])
+}
+
+func (suite *TenantTestSuite) TestTenantNotAllowedFromHeaderIsDefault() {
+	suite.Equal(http.StatusOK, suite.track("passenger").Code)
+	suite.Equal(Default, suite.tenantID)
+	suite.Equal(string(Default), suite.loggerData[constants.Tenant])
+}
+
+func (suite *TenantTestSuite) TestInvalidTenantHeaderIsRejected() {
+	suite.Equal(http.StatusBadRequest, suite.track("cargo/../passenger").Code)
+}
+
+func (suite *TenantTestSuite) TestTenantFromPrincipal() {
+	suite.principal = &auth.Principal{Subject: "desk", Tenant: "passenger"}
+
+	suite.Equal(http.StatusOK, suite.track("").Code)
+	suite.Equal(ID("passenger"), suite.tenantID)
+}
+
+func (suite *TenantTestSuite) TestPrincipalCannotSelectOtherTenant() {
+	suite.principal = &auth.Principal{Subject: "desk", Tenant: "passenger"}
+
+	suite.Equal(http.StatusForbidden, suite.track("cargo").Code)
+}
+
+func (suite *TenantTestSuite) TestPrincipalWithoutTenantCannotSelectTenant() {
+	suite.principal = &auth.Principal{Subject: "desk"}
+
+	suite.Equal(http.StatusForbidden, suite.track("cargo").Code)
+}
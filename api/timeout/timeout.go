@@ -0,0 +1,71 @@
+package timeout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//RouteTimeouts gives the deadline of each route
+type RouteTimeouts interface {
+	Get(route string) time.Duration
+}
+
+//Timeouts holds the deadline of each route, it can be replaced while requests are served
+type Timeouts struct {
+	mu       sync.RWMutex
+	timeouts map[string]time.Duration
+	fallback time.Duration
+}
+
+//NewTimeouts returns the timeouts of the routes, routes without a timeout get the fallback
+func NewTimeouts(timeouts map[string]time.Duration, fallback time.Duration) *Timeouts {
+	return &Timeouts{timeouts: timeouts, fallback: fallback}
+}
+
+func (t *Timeouts) Get(route string) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if timeout, ok := t.timeouts[route]; ok {
+		return timeout
+	}
+	return t.fallback
+}
+
+//Replace swaps every route timeout in one step
+func (t *Timeouts) Replace(timeouts map[string]time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timeouts = timeouts
+}
+
+//TimeoutMiddleware gives the request context the deadline of the route, a zero timeout sets none.
+//Handlers stop when the context is done; if one still returns without a response after the
+//deadline the request fails with ERR_API_TIMEOUT
+func TimeoutMiddleware(timeouts RouteTimeouts, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := timeouts.Get(route)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+			logging.GetLogger(c).
+				WithField(constants.Interface, "TimeoutMiddleware").
+				WithField(constants.Method, route).
+				Errorf("Deadline of %s exceeded - %s", timeout, errors.ErrTimeout.Error())
+			errors.AbortWithErrorResponse(c, errors.ErrTimeout)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package timeout
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type TimeoutTestSuite struct {
+	suite.Suite
+	timeouts *Timeouts
+	metrics  *metrics.Metrics
+	router   *gin.Engine
+}
+
+func TestTimeout(t *testing.T) {
+	suite.Run(t, new(TimeoutTestSuite))
+}
+
+func (suite *TimeoutTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.timeouts = NewTimeouts(map[string]time.Duration{"/slow": 10 * time.Millisecond, "/unlimited": 0}, time.Second)
+	suite.metrics = metrics.NewMetrics()
+	suite.router = gin.New()
+	suite.router.Use(metrics.MetricsMiddleware(suite.metrics))
+	suite.router.GET("/metrics", suite.metrics.Handler())
+	for _, route := range []string{"/slow", "/unlimited"} {
+		suite.router.GET(route, TimeoutMiddleware(suite.timeouts, route), func(c *gin.Context) {
+			if _, ok := c.Request.Context().Deadline(); !ok {
+				c.String(http.StatusOK, "no deadline")
+				return
+			}
+			//a handler which ignores the deadline
+			time.Sleep(20 * time.Millisecond)
+		})
+	}
+}
+
+func (suite *TimeoutTestSuite) serve(target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *TimeoutTestSuite) TestDeadlineExceededReturnsTimeout() {
+	recorder := suite.serve("/slow")
+
+	suite.Equal(http.StatusGatewayTimeout, recorder.Code)
+	suite.Contains(recorder.Body.String(), errors.Timeout)
+	suite.Contains(suite.serve("/metrics").Body.String(), `flight_paths_tracker_errors_total{error_code="ERR_API_TIMEOUT",tenant="default"} 1`)
+}
+
+func (suite *TimeoutTestSuite) TestZeroTimeoutSetsNoDeadline() {
+	recorder := suite.serve("/unlimited")
+
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("no deadline", recorder.Body.String())
+}
+
+func (suite *TimeoutTestSuite) TestReplaceAndFallback() {
+	suite.Equal(time.Second, suite.timeouts.Get("/other"))
+
+	suite.timeouts.Replace(map[string]time.Duration{"/slow": 0})
+
+	suite.Equal(time.Duration(0), suite.timeouts.Get("/slow"))
+	suite.Equal(http.StatusOK, suite.serve("/slow").Code)
+}
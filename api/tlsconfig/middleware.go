@@ -0,0 +1,30 @@
+package tlsconfig
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//ClientCertMiddleware exposes the subject of a verified client certificate as the caller
+//identity of the request and adds it to the request logger
+func ClientCertMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			subject := c.Request.TLS.VerifiedChains[0][0].Subject.String()
+			c.Set(constants.CLIENT_SUBJECT_KEY, subject)
+			c.Set(constants.LOGGER_KEY, logging.GetLogger(c).WithField(constants.ClientSubject, subject))
+		}
+		c.Next()
+	}
+}
+
+//GetClientSubject returns the subject of the verified client certificate, if any
+func GetClientSubject(c *gin.Context) (string, bool) {
+	subject, ok := c.Get(constants.CLIENT_SUBJECT_KEY)
+	if !ok {
+		return "", false
+	}
+	s, ok := subject.(string)
+	return s, ok
+}
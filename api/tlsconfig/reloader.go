@@ -0,0 +1,162 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+)
+
+//Reloader serves the certificate and client CAs last loaded from disk, so renewed
+//files are picked up without restarting the server
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	modTimes    map[string]time.Time
+}
+
+//NewReloader loads the certificate and key, and the client CA bundle when one is given
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//Reload reads every file again, the previous certificate is kept when any file is invalid
+func (r *Reloader) Reload() error {
+	modTimes, err := r.currentModTimes()
+	if err != nil {
+		return err
+	}
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load certificate: %v", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		bundle, err := ioutil.ReadFile(r.clientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("no certificate found in client CA bundle %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+//Watch reloads the files whenever one of them changes on disk, until the context is done
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	logger := logging.NewLoggerEntry().
+		WithField(constants.Interface, "Reloader").
+		WithField(constants.Method, "Watch")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logger.Errorf("Could not reload TLS files, keeping the previous certificate - %s", err.Error())
+				continue
+			}
+			logger.Infof("Reloaded TLS certificate from %s", r.certFile)
+		}
+	}
+}
+
+//TLSConfig returns a server config which always uses the last loaded files. Client
+//certificates are required and verified when a client CA bundle is configured
+func (r *Reloader) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: r.getCertificate,
+	}
+	if r.clientCAFile != "" {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = r.getClientCAs()
+		//every handshake gets the client CAs last loaded
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			clientConfig := config.Clone()
+			clientConfig.GetConfigForClient = nil
+			clientConfig.ClientCAs = r.getClientCAs()
+			return clientConfig, nil
+		}
+	}
+	return config
+}
+
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.certificate, nil
+}
+
+func (r *Reloader) getClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clientCAs
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) currentModTimes() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.currentModTimes()
+	if err != nil {
+		//files being replaced may be missing for a moment, they are checked again on the next tick
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
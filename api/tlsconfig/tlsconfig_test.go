@@ -0,0 +1,166 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+type TLSConfigTestSuite struct {
+	suite.Suite
+	dir           string
+	ca            keyPair
+	clientSubject string
+	server        *httptest.Server
+	reloader      *Reloader
+}
+
+func TestTLSConfig(t *testing.T) {
+	suite.Run(t, new(TLSConfigTestSuite))
+}
+
+func (suite *TLSConfigTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.ca = suite.newCertificate("Test CA", nil, 1)
+	suite.writeCertificate("ca.pem", "", suite.ca)
+	suite.writeCertificate("server.pem", "server-key.pem", suite.newCertificate("localhost", &suite.ca, 2))
+
+	var err error
+	suite.reloader, err = NewReloader(suite.path("server.pem"), suite.path("server-key.pem"), suite.path("ca.pem"))
+	suite.Require().Nil(err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ClientCertMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		suite.clientSubject, _ = GetClientSubject(c)
+		c.Status(http.StatusOK)
+	})
+	suite.server = httptest.NewUnstartedServer(router)
+	suite.server.TLS = suite.reloader.TLSConfig()
+	suite.server.StartTLS()
+}
+
+func (suite *TLSConfigTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *TLSConfigTestSuite) path(name string) string {
+	return filepath.Join(suite.dir, name)
+}
+
+func (suite *TLSConfigTestSuite) newCertificate(commonName string, parent *keyPair, serial int64) keyPair {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().Nil(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Flight Paths"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	issuer, signer := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	suite.Require().Nil(err)
+	cert, err := x509.ParseCertificate(der)
+	suite.Require().Nil(err)
+	return keyPair{cert: cert, key: key}
+}
+
+func (suite *TLSConfigTestSuite) writeCertificate(certFile, keyFile string, pair keyPair) {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pair.cert.Raw})
+	suite.Require().Nil(ioutil.WriteFile(suite.path(certFile), certPEM, 0600))
+	if keyFile != "" {
+		der, err := x509.MarshalECPrivateKey(pair.key)
+		suite.Require().Nil(err)
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		suite.Require().Nil(ioutil.WriteFile(suite.path(keyFile), keyPEM, 0600))
+	}
+}
+
+func (suite *TLSConfigTestSuite) get(client *keyPair) (*http.Response, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(suite.ca.cert)
+	config := &tls.Config{RootCAs: roots}
+	if client != nil {
+		config.Certificates = []tls.Certificate{{Certificate: [][]byte{client.cert.Raw}, PrivateKey: client.key}}
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	return httpClient.Get(suite.server.URL)
+}
+
+func (suite *TLSConfigTestSuite) TestClientCertificateSubjectIsExposed() {
+	client := suite.newCertificate("batch-jobs", &suite.ca, 3)
+	resp, err := suite.get(&client)
+
+	suite.Require().Nil(err)
+	resp.Body.Close()
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal("CN=batch-jobs,O=Flight Paths", suite.clientSubject)
+}
+
+func (suite *TLSConfigTestSuite) TestClientWithoutCertificateIsRejected() {
+	_, err := suite.get(nil)
+
+	suite.NotNil(err)
+}
+
+func (suite *TLSConfigTestSuite) TestClientCertificateFromOtherCAIsRejected() {
+	otherCA := suite.newCertificate("Other CA", nil, 4)
+	client := suite.newCertificate("intruder", &otherCA, 5)
+	_, err := suite.get(&client)
+
+	suite.NotNil(err)
+}
+
+func (suite *TLSConfigTestSuite) TestReloadServesNewCertificate() {
+	client := suite.newCertificate("batch-jobs", &suite.ca, 3)
+	suite.writeCertificate("server.pem", "server-key.pem", suite.newCertificate("localhost", &suite.ca, 42))
+	suite.True(suite.reloader.changed())
+	suite.Require().Nil(suite.reloader.Reload())
+	suite.False(suite.reloader.changed())
+
+	resp, err := suite.get(&client)
+	suite.Require().Nil(err)
+	resp.Body.Close()
+	suite.Equal(int64(42), resp.TLS.PeerCertificates[0].SerialNumber.Int64())
+}
+
+func (suite *TLSConfigTestSuite) TestInvalidReloadKeepsPreviousCertificate() {
+	suite.Require().Nil(ioutil.WriteFile(suite.path("server.pem"), []byte("not a certificate"), 0600))
+
+	suite.NotNil(suite.reloader.Reload())
+	certificate, _ := suite.reloader.getCertificate(nil)
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	suite.Require().Nil(err)
+	suite.Equal(int64(2), leaf.SerialNumber.Int64())
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+//exportedSpan is the JSON line written for each finished span
+type exportedSpan struct {
+	TraceID         string                 `json:"trace_id"`
+	SpanID          string                 `json:"span_id"`
+	ParentSpanID    string                 `json:"parent_span_id,omitempty"`
+	Name            string                 `json:"name"`
+	Kind            string                 `json:"kind"`
+	StartTime       time.Time              `json:"start_time"`
+	EndTime         time.Time              `json:"end_time"`
+	DurationMs      float64                `json:"duration_ms"`
+	StatusCode      int32                  `json:"status_code"`
+	StatusMessage   string                 `json:"status_message,omitempty"`
+	HasRemoteParent bool                   `json:"has_remote_parent,omitempty"`
+	Attributes      map[string]interface{} `json:"attributes,omitempty"`
+	Annotations     []exportedAnnotation   `json:"annotations,omitempty"`
+}
+
+type exportedAnnotation struct {
+	Time       time.Time              `json:"time"`
+	Message    string                 `json:"message"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+//jsonExporter writes every span as one line of JSON, it suits stdout and local files
+type jsonExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+//NewJSONExporter returns an exporter writing spans as JSON lines to w
+func NewJSONExporter(w io.Writer) trace.Exporter {
+	return &jsonExporter{w: w}
+}
+
+func (e *jsonExporter) ExportSpan(s *trace.SpanData) {
+	span := exportedSpan{
+		TraceID:         s.TraceID.String(),
+		SpanID:          s.SpanID.String(),
+		Name:            s.Name,
+		Kind:            spanKind(s.SpanKind),
+		StartTime:       s.StartTime,
+		EndTime:         s.EndTime,
+		DurationMs:      float64(s.EndTime.Sub(s.StartTime)) / float64(time.Millisecond),
+		StatusCode:      s.Code,
+		StatusMessage:   s.Message,
+		HasRemoteParent: s.HasRemoteParent,
+		Attributes:      s.Attributes,
+	}
+	if s.ParentSpanID != (trace.SpanID{}) {
+		span.ParentSpanID = s.ParentSpanID.String()
+	}
+	for _, annotation := range s.Annotations {
+		span.Annotations = append(span.Annotations, exportedAnnotation{
+			Time:       annotation.Time,
+			Message:    annotation.Message,
+			Attributes: annotation.Attributes,
+		})
+	}
+
+	line, err := json.Marshal(span)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.w.Write(append(line, '\n'))
+}
+
+func spanKind(kind int) string {
+	switch kind {
+	case trace.SpanKindServer:
+		return "server"
+	case trace.SpanKindClient:
+		return "client"
+	}
+	return "unspecified"
+}
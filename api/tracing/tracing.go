@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+)
+
+//Exporters which can be configured
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+//Span attributes
+const (
+	RequestIDAttribute = "request.id"
+	TicketsAttribute   = "tickets.count"
+	ErrorCodeAttribute = "error.code"
+)
+
+var traceContextFormat = &tracecontext.HTTPFormat{}
+
+//Setup registers the exporter and the sampling rate of spans. The returned closer
+//must be closed on shutdown when the exporter writes to a file
+func Setup(exporter string, file string, sampleRate float64) (io.Closer, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("trace sample rate %v is not between 0 and 1", sampleRate)
+	}
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(sampleRate)})
+
+	switch exporter {
+	case "", ExporterNone:
+		return nopCloser{}, nil
+	case ExporterStdout:
+		trace.RegisterExporter(NewJSONExporter(os.Stdout))
+		return nopCloser{}, nil
+	case ExporterFile:
+		if file == "" {
+			return nil, fmt.Errorf("the %s trace exporter needs a file", ExporterFile)
+		}
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		trace.RegisterExporter(NewJSONExporter(f))
+		return f, nil
+	}
+	return nil, fmt.Errorf("not a valid trace exporter: %q", exporter)
+}
+
+//TracingMiddleware starts a server span for every request. A W3C traceparent header makes
+//the span a child of the caller's span. The request logger is bound to the span so its
+//entries are annotated on it
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.FullPath()
+		if name == "" {
+			name = c.Request.URL.Path
+		}
+		name = c.Request.Method + " " + name
+
+		var ctx context.Context
+		var span *trace.Span
+		if parent, ok := traceContextFormat.SpanContextFromRequest(c.Request); ok {
+			ctx, span = trace.StartSpanWithRemoteParent(c.Request.Context(), name, parent, trace.WithSpanKind(trace.SpanKindServer))
+		} else {
+			ctx, span = trace.StartSpan(c.Request.Context(), name, trace.WithSpanKind(trace.SpanKindServer))
+		}
+		defer span.End()
+
+		span.AddAttributes(
+			trace.StringAttribute(ochttp.MethodAttribute, c.Request.Method),
+			trace.StringAttribute(ochttp.PathAttribute, c.Request.URL.Path),
+			trace.StringAttribute(ochttp.UserAgentAttribute, c.Request.UserAgent()),
+			trace.StringAttribute(RequestIDAttribute, requestid.Get(c)),
+		)
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(constants.LOGGER_KEY, logging.GetLogger(c).WithContext(ctx))
+		c.Next()
+
+		status := c.Writer.Status()
+		span.AddAttributes(trace.Int64Attribute(ochttp.StatusCodeAttribute, int64(status)))
+		span.SetStatus(ochttp.TraceStatus(status, ""))
+	}
+}
+
+//StartSpan starts a child span of the request span, the span must be ended by the caller
+func StartSpan(c *gin.Context, name string) (context.Context, *trace.Span) {
+	ctx := context.Background()
+	if c != nil && c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	return trace.StartSpan(ctx, name)
+}
+
+//SetErrorStatus marks the span as failed with the error response
+func SetErrorStatus(span *trace.Span, e *errors.ErrorResponse) {
+	span.AddAttributes(trace.StringAttribute(ErrorCodeAttribute, string(e.ErrorCode)))
+	span.SetStatus(trace.Status{Code: ochttp.TraceStatus(e.HttpStatusCode, "").Code, Message: e.ErrorMessage})
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
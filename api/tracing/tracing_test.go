@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+type TracingTestSuite struct {
+	suite.Suite
+	exporter *spanRecorder
+	router   *gin.Engine
+}
+
+func TestTracing(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+func (suite *TracingTestSuite) SetupTest() {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	suite.exporter = &spanRecorder{}
+	trace.RegisterExporter(suite.exporter)
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()), requestid.New(), TracingMiddleware())
+	suite.router.POST("/track", func(c *gin.Context) {
+		logging.GetLogger(c).Info("tracking tickets")
+		_, span := StartSpan(c, "child")
+		SetErrorStatus(span, errors.ErrUnableToTrack)
+		span.End()
+		c.Status(http.StatusUnprocessableEntity)
+	})
+}
+
+func (suite *TracingTestSuite) TearDownTest() {
+	trace.UnregisterExporter(suite.exporter)
+}
+
+func (suite *TracingTestSuite) track(traceparent string) {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/track", nil)
+	if traceparent != "" {
+		req.Header.Set("traceparent", traceparent)
+	}
+	suite.router.ServeHTTP(recorder, req)
+}
+
+func (suite *TracingTestSuite) TestServerSpanWithChildSpan() {
+	suite.track("")
+
+	suite.Require().Len(suite.exporter.spans, 2)
+	child, server := suite.exporter.spans[0], suite.exporter.spans[1]
+
+	suite.Equal("POST /track", server.Name)
+	suite.Equal(trace.SpanKindServer, server.SpanKind)
+	suite.Equal(int64(http.StatusUnprocessableEntity), server.Attributes["http.status_code"])
+	suite.NotEmpty(server.Attributes[RequestIDAttribute])
+	suite.Require().Len(server.Annotations, 1)
+	suite.Equal("tracking tickets", server.Annotations[0].Message)
+
+	suite.Equal("child", child.Name)
+	suite.Equal(server.SpanID, child.ParentSpanID)
+	suite.Equal(server.TraceID, child.TraceID)
+	suite.Equal(string(errors.UnableToTrack), child.Attributes[ErrorCodeAttribute])
+	suite.NotEqual(int32(trace.StatusCodeOK), child.Code)
+}
+
+func (suite *TracingTestSuite) TestServerSpanHonoursTraceparent() {
+	suite.track("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	suite.Require().Len(suite.exporter.spans, 2)
+	server := suite.exporter.spans[1]
+
+	suite.Equal("4bf92f3577b34da6a3ce929d0e0e4736", server.TraceID.String())
+	suite.Equal("00f067aa0ba902b7", server.ParentSpanID.String())
+	suite.True(server.HasRemoteParent)
+}
+
+func (suite *TracingTestSuite) TestJSONExporterWritesOneLinePerSpan() {
+	var out bytes.Buffer
+	exporter := NewJSONExporter(&out)
+	trace.RegisterExporter(exporter)
+	defer trace.UnregisterExporter(exporter)
+
+	suite.track("")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	suite.Require().Len(lines, 2)
+	var span exportedSpan
+	suite.Nil(json.Unmarshal(lines[1], &span))
+	suite.Equal("POST /track", span.Name)
+	suite.Equal("server", span.Kind)
+	suite.Equal("tracking tickets", span.Annotations[0].Message)
+}
+
+func (suite *TracingTestSuite) TestSetupRejectsUnknownExporter() {
+	_, err := Setup("zipkin", "", 1)
+	suite.NotNil(err)
+
+	_, err = Setup(ExporterFile, "", 1)
+	suite.NotNil(err)
+
+	_, err = Setup(ExporterNone, "", 2)
+	suite.NotNil(err)
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"flag"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -9,35 +12,211 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/admin"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/cli"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/config"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/constants"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/health"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/logging"
 	"github.com/kumareswaramoorthi/flight-paths-tracker/api/router"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tlsconfig"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/api/tracing"
 )
 
 func main() {
 
-	ginEngine := router.SetupRouter()
+	//subcommands such as track run instead of the server
+	if len(os.Args) > 1 {
+		if command, ok := cli.Commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		}
+	}
+
+	cfg, err := config.Load(os.Args[1:], os.Getenv, os.Stderr)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid config: %v\n", err)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Could not print config: %v\n", err)
+		}
+		return
+	}
+
+	//logs go to the configured sink until the server stops
+	logCloser, err := logging.NewLoggerEntry().SetOutput(logging.Output{
+		Sink:       cfg.Log.Output.Sink,
+		File:       cfg.Log.Output.File,
+		MaxSizeMB:  cfg.Log.Output.MaxSizeMB,
+		MaxAgeDays: cfg.Log.Output.MaxAgeDays,
+		MaxBackups: cfg.Log.Output.MaxBackups,
+		SyslogTag:  cfg.Log.Output.SyslogTag,
+	})
+	if err != nil {
+		log.Fatalf("Could not set log output: %v\n", err)
+	}
+	defer logCloser.Close()
+
+	//personal data is redacted from logs and spans
+	if err := setRedaction(cfg.Log.Redaction); err != nil {
+		log.Fatalf("Could not set log redaction: %v\n", err)
+	}
+
+	//log level and format apply to the global logger shared by every request,
+	//the admin API may override them at runtime
+	logSettings, err := admin.NewLogSettings(logging.NewLoggerEntry(), cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		log.Fatalf("Could not set log settings: %v\n", err)
+	}
+	gin.SetMode(cfg.Server.GinMode)
+
+	//tracing is set up before the router so no span is missed
+	traceCloser, err := tracing.Setup(cfg.Tracing.Exporter, cfg.Tracing.File, cfg.Tracing.SampleRate)
+	if err != nil {
+		log.Fatalf("Could not set up tracing: %v\n", err)
+	}
+	defer traceCloser.Close()
+
+	apiHealth := health.NewHealth()
+	ginEngine, reloadables := router.SetupRouter(cfg, apiHealth)
 
 	srv := &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.Server.Addr,
 		Handler: ginEngine,
 	}
 
-	// Graceful shut down of server
-	graceful := make(chan os.Signal)
-	signal.Notify(graceful, syscall.SIGINT)
-	signal.Notify(graceful, syscall.SIGTERM)
+	//TLS certificates are reloaded from disk until the server stops
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	var reloader *tlsconfig.Reloader
+	if cfg.TLS.Enabled() {
+		reloader, err = tlsconfig.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile)
+		if err != nil {
+			log.Fatalf("Could not load TLS files: %v\n", err)
+		}
+		srv.TLSConfig = reloader.TLSConfig()
+		if cfg.TLS.ReloadInterval > 0 {
+			go reloader.Watch(watchCtx, cfg.TLS.ReloadInterval.Duration())
+		}
+	}
+
+	//the admin API listens on its own address so it can be kept private, it is served over TLS
+	//with the certificates of the server so admin credentials are never sent in clear
+	var adminSrv *http.Server
+	if cfg.Admin.Addr != "" {
+		adminRouter, err := router.SetupAdminRouter(cfg, logSettings, reloadables)
+		if err != nil {
+			log.Fatalf("Could not set up the admin API: %v\n", err)
+		}
+		adminSrv = &http.Server{
+			Addr:    cfg.Admin.Addr,
+			Handler: adminRouter,
+		}
+		if reloader != nil {
+			adminSrv.TLSConfig = reloader.TLSConfig()
+		}
+		go func() {
+			var err error
+			if adminSrv.TLSConfig != nil {
+				log.Printf("Listening admin server with TLS on %s\n", cfg.Admin.Addr)
+				err = adminSrv.ListenAndServeTLS("", "")
+			} else {
+				log.Printf("Listening admin server on %s\n", cfg.Admin.Addr)
+				err = adminSrv.ListenAndServe()
+			}
+			if err != http.ErrServerClosed {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	// Graceful shut down of server, SIGHUP reloads the config
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	//the reloaded config is only seen by this goroutine, the settings read by the main goroutine
+	//take effect after a restart anyway
+	current := cfg
 	go func() {
-		<-graceful
+		for sig := range signals {
+			if sig == syscall.SIGHUP {
+				current = reload(current, reloadables, logSettings)
+				continue
+			}
+			break
+		}
+		//fail the readiness probe first so no new traffic is routed to the server while it drains
+		apiHealth.SetDraining()
+		log.Printf("Draining for %s before shutting down...\n", current.Server.DrainDelay)
+		time.Sleep(current.Server.DrainDelay.Duration())
 		log.Println("Shutting down ctrl...")
-		ctx, cancelFunc := context.WithTimeout(context.Background(), 20*time.Second)
+		ctx, cancelFunc := context.WithTimeout(context.Background(), current.Server.ShutdownTimeout.Duration())
 		defer cancelFunc()
+		if adminSrv != nil {
+			if err := adminSrv.Shutdown(ctx); err != nil {
+				log.Printf("Could not do graceful shutdown of the admin server: %v\n", err)
+			}
+		}
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Fatalf("Could not do graceful shutdown: %v\n", err)
 		}
 	}()
 
-	log.Println("Listening server on 8080")
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatal("")
+	if cfg.TLS.Enabled() {
+		log.Printf("Listening server with TLS on %s\n", cfg.Server.Addr)
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		log.Printf("Listening server on %s\n", cfg.Server.Addr)
+		err = srv.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
 	}
 	log.Println("Server gracefully stopped...")
 }
+
+//reload loads the config again and applies the log settings, rate limits and airports.
+//On any error the current config stays in effect and is returned
+func reload(current *config.Config, reloadables *router.Reloadables, logSettings admin.LogSettings) *config.Config {
+	apiLogger := logging.NewLoggerEntry().
+		WithField(constants.Interface, "main").
+		WithField(constants.Method, "reload")
+
+	next, err := config.Load(os.Args[1:], os.Getenv, ioutil.Discard)
+	if err == nil {
+		err = reloadables.Reload(next)
+	}
+	if err != nil {
+		apiLogger.Errorf("Config reload failed, keeping current config - %s", err.Error())
+		return current
+	}
+	//an active admin override of the log settings is kept until it is reset or expires
+	if err := logSettings.SetDefaults(next.Log.Level, next.Log.Format); err != nil {
+		apiLogger.Errorf("Config reload could not apply log settings - %s", err.Error())
+	}
+
+	for _, setting := range config.RestartRequired(current, next) {
+		apiLogger.Warnf("Config reload ignored %s, it takes effect after a restart", setting)
+	}
+	apiLogger.Info("Config reloaded")
+	return next
+}
+
+func setRedaction(cfg config.LogRedactionConfig) error {
+	redaction := logging.Redaction{
+		Fields:   cfg.Fields,
+		Patterns: cfg.Patterns,
+		Mode:     cfg.Mode,
+	}
+	if cfg.HMACKeyFile != "" {
+		key, err := ioutil.ReadFile(cfg.HMACKeyFile)
+		if err != nil {
+			return err
+		}
+		redaction.HMACKey = bytes.TrimSpace(key)
+	}
+	return logging.SetRedaction(redaction)
+}
@@ -0,0 +1,216 @@
+//Package client calls the flight paths tracker API from Go programs
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
+)
+
+//Headers sent by the client
+const (
+	RequestIDHeader = "X-Request-ID"
+	APIKeyHeader    = "X-API-Key"
+	TenantHeader    = "X-Tenant-ID"
+)
+
+//Defaults of the Config
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+//Config of a Client. Failed requests are retried MaxRetries times on a 429 or 5xx response or a
+//network error, waiting an exponential backoff from MinBackoff to MaxBackoff, or the Retry-After
+//of the response when it is longer
+type Config struct {
+	BaseURL     string
+	HTTPClient  *http.Client
+	APIKey      string
+	BearerToken string
+	Tenant      string
+	MaxRetries  int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+type Client interface {
+	//Track returns the first origin and last destination of the tickets. API errors are returned as *Error
+	Track(ctx context.Context, tickets []flightpath.Ticket) (flightpath.Itinerary, error)
+}
+
+type client struct {
+	config Config
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func NewClient(config Config) (Client, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("client: BaseURL is required")
+	}
+	if config.MaxRetries < 0 {
+		return nil, fmt.Errorf("client: MaxRetries must not be negative")
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = DefaultMinBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	return &client{
+		config: config,
+		sleep:  sleep,
+	}, nil
+}
+
+type requestIDKey struct{}
+
+//WithRequestID sets the request ID sent with the calls made with the context, so they can be
+//found in the API logs. Calls without one get a random ID, the same for every retry
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func (cl *client) Track(ctx context.Context, tickets []flightpath.Ticket) (flightpath.Itinerary, error) {
+	body := struct {
+		Tickets [][]string `json:"tickets"`
+	}{Tickets: make([][]string, len(tickets))}
+	for i, ticket := range tickets {
+		body.Tickets[i] = []string{ticket.Origin, ticket.Destination}
+	}
+
+	var srcdst []string
+	if err := cl.do(ctx, http.MethodPost, "/track", body, &srcdst); err != nil {
+		return flightpath.Itinerary{}, err
+	}
+	if len(srcdst) != 2 {
+		return flightpath.Itinerary{}, fmt.Errorf("client: unexpected track response %v", srcdst)
+	}
+	return flightpath.Itinerary{Source: srcdst[0], Destination: srcdst[1]}, nil
+}
+
+//do sends the request, retrying it when allowed, and decodes the response into out
+func (cl *client) do(ctx context.Context, method, path string, in interface{}, out interface{}) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := cl.attempt(ctx, method, path, payload, requestID, out)
+		if err == nil || retryAfter < 0 || attempt >= cl.config.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+		if err := cl.sleep(ctx, cl.backoff(attempt, retryAfter)); err != nil {
+			return err
+		}
+	}
+}
+
+//attempt sends the request once. retryAfter is negative when the error must not be retried
+func (cl *client) attempt(ctx context.Context, method, path string, payload []byte, requestID string, out interface{}) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, cl.config.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(RequestIDHeader, requestID)
+	if cl.config.APIKey != "" {
+		req.Header.Set(APIKeyHeader, cl.config.APIKey)
+	}
+	if cl.config.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cl.config.BearerToken)
+	}
+	if cl.config.Tenant != "" {
+		req.Header.Set(TenantHeader, cl.config.Tenant)
+	}
+
+	resp, err := cl.config.HTTPClient.Do(req)
+	if err != nil {
+		//network errors are retried
+		return 0, err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return -1, json.Unmarshal(content, out)
+	}
+
+	apiError := &Error{Status: resp.StatusCode}
+	//a body which is not an ErrorResponse still gives an error with the status
+	_ = json.Unmarshal(content, apiError)
+	apiError.Status = resp.StatusCode
+	apiError.RequestID = requestID
+	if id := resp.Header.Get(RequestIDHeader); id != "" {
+		apiError.RequestID = id
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, apiError
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After")), apiError
+}
+
+//backoff doubles from MinBackoff up to MaxBackoff with jitter, a longer Retry-After wins
+func (cl *client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := float64(cl.config.MinBackoff) * math.Pow(2, float64(attempt))
+	backoff = math.Min(backoff, float64(cl.config.MaxBackoff))
+	//wait between half and all of the backoff so clients do not retry in step
+	wait := time.Duration(backoff/2 + mrand.Float64()*backoff/2)
+	if retryAfter > wait {
+		return retryAfter
+	}
+	return wait
+}
+
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "github.com/kumareswaramoorthi/flight-paths-tracker/api/errors"
+	"github.com/kumareswaramoorthi/flight-paths-tracker/pkg/flightpath"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+	server    *httptest.Server
+	responses []func(w http.ResponseWriter)
+	requests  []*http.Request
+	sleeps    []time.Duration
+}
+
+func TestClient(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
+
+func (suite *ClientTestSuite) SetupTest() {
+	suite.responses = nil
+	suite.requests = nil
+	suite.sleeps = nil
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.requests = append(suite.requests, r)
+		respond := suite.responses[0]
+		if len(suite.responses) > 1 {
+			suite.responses = suite.responses[1:]
+		}
+		respond(w)
+	}))
+}
+
+func (suite *ClientTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ClientTestSuite) newClient(config Config) Client {
+	config.BaseURL = suite.server.URL + "/"
+	cl, err := NewClient(config)
+	suite.Require().Nil(err)
+	cl.(*client).sleep = func(ctx context.Context, d time.Duration) error {
+		suite.sleeps = append(suite.sleeps, d)
+		return nil
+	}
+	return cl
+}
+
+func respondJSON(status int, body interface{}, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+var tickets = []flightpath.Ticket{{Origin: "IND", Destination: "EWR"}, {Origin: "SFO", Destination: "IND"}}
+
+func (suite *ClientTestSuite) TestTrack() {
+	suite.responses = append(suite.responses, respondJSON(http.StatusOK, []string{"SFO", "EWR"}))
+	cl := suite.newClient(Config{APIKey: "key", Tenant: "acme"})
+
+	itinerary, err := cl.Track(WithRequestID(context.Background(), "req-1"), tickets)
+
+	suite.Nil(err)
+	suite.Equal(flightpath.Itinerary{Source: "SFO", Destination: "EWR"}, itinerary)
+	suite.Require().Len(suite.requests, 1)
+	request := suite.requests[0]
+	suite.Equal("/track", request.URL.Path)
+	suite.Equal("req-1", request.Header.Get(RequestIDHeader))
+	suite.Equal("key", request.Header.Get(APIKeyHeader))
+	suite.Equal("acme", request.Header.Get(TenantHeader))
+	suite.Empty(request.Header.Get("Authorization"))
+}
+
+func (suite *ClientTestSuite) TestErrorResponseMatchesErrorCode() {
+	suite.responses = append(suite.responses, respondJSON(http.StatusBadRequest, apierrors.ErrorResponse{
+		HttpStatusCode: http.StatusBadRequest,
+		ErrorCode:      apierrors.InvalidTicket,
+		ErrorMessage:   "invalid ticket",
+		Errors:         []apierrors.ValidationError{{Ticket: 1, Field: apierrors.FieldOrigin, Value: "sfo", Reason: apierrors.ReasonLowercase}},
+	}, RequestIDHeader, "req-2"))
+	cl := suite.newClient(Config{MaxRetries: 3})
+
+	_, err := cl.Track(context.Background(), tickets)
+
+	suite.True(stderrors.Is(err, InvalidTicket))
+	suite.False(stderrors.Is(err, UnableToTrack))
+	var apiError *Error
+	suite.Require().True(stderrors.As(err, &apiError))
+	suite.Equal(http.StatusBadRequest, apiError.Status)
+	suite.Equal("req-2", apiError.RequestID)
+	suite.Equal([]ValidationError{{Ticket: 1, Field: "origin", Value: "sfo", Reason: "LOWERCASE"}}, apiError.Errors)
+	//client errors are not retried
+	suite.Len(suite.requests, 1)
+	suite.Empty(suite.sleeps)
+}
+
+func (suite *ClientTestSuite) TestRetriesRateLimitedAndServerErrors() {
+	suite.responses = append(suite.responses,
+		respondJSON(http.StatusTooManyRequests, apierrors.ErrRateLimited, "Retry-After", "7"),
+		respondJSON(http.StatusBadGateway, "bad gateway"),
+		respondJSON(http.StatusOK, []string{"SFO", "EWR"}),
+	)
+	cl := suite.newClient(Config{MaxRetries: 2, MinBackoff: time.Second, MaxBackoff: 4 * time.Second})
+
+	itinerary, err := cl.Track(context.Background(), tickets)
+
+	suite.Nil(err)
+	suite.Equal("SFO", itinerary.Source)
+	suite.Require().Len(suite.requests, 3)
+	requestID := suite.requests[0].Header.Get(RequestIDHeader)
+	suite.NotEmpty(requestID)
+	for _, request := range suite.requests {
+		suite.Equal(requestID, request.Header.Get(RequestIDHeader))
+	}
+	suite.Require().Len(suite.sleeps, 2)
+	suite.Equal(7*time.Second, suite.sleeps[0])
+	suite.True(suite.sleeps[1] >= time.Second && suite.sleeps[1] <= 2*time.Second, "%s", suite.sleeps[1])
+}
+
+func (suite *ClientTestSuite) TestGivesUpAfterMaxRetries() {
+	suite.responses = append(suite.responses, respondJSON(http.StatusGatewayTimeout, apierrors.ErrTimeout))
+	cl := suite.newClient(Config{MaxRetries: 1})
+
+	_, err := cl.Track(context.Background(), tickets)
+
+	suite.True(stderrors.Is(err, Timeout))
+	suite.Len(suite.requests, 2)
+}
+
+func (suite *ClientTestSuite) TestInvalidConfig() {
+	_, err := NewClient(Config{})
+	suite.NotNil(err)
+	_, err = NewClient(Config{BaseURL: "http://localhost", MaxRetries: -1})
+	suite.NotNil(err)
+}
+
+//TestErrorCodesMatchTheAPI reads the error codes declared in the sources of the client and of the API,
+//so a code added to or renamed in the API fails here until the client declares it too
+func (suite *ClientTestSuite) TestErrorCodesMatchTheAPI() {
+	clientCodes := suite.declaredErrorCodes("errors.go")
+	apiCodes := suite.declaredErrorCodes(filepath.Join("..", "..", "api", "errors", "errors.go"))
+
+	suite.NotEmpty(clientCodes)
+	suite.Equal(apiCodes, clientCodes)
+	for code := range clientCodes {
+		suite.Contains(apierrors.ApiErrors, apierrors.ErrorCode(code))
+	}
+	suite.Len(apierrors.ApiErrors, len(clientCodes))
+}
+
+//declaredErrorCodes returns the values of the ERR_API_ string constants of a Go file
+func (suite *ClientTestSuite) declaredErrorCodes(path string) map[string]bool {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	suite.Require().Nil(err)
+	codes := make(map[string]bool)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			for _, value := range spec.(*ast.ValueSpec).Values {
+				literal, ok := value.(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING {
+					continue
+				}
+				if code, err := strconv.Unquote(literal.Value); err == nil && strings.HasPrefix(code, "ERR_API_") {
+					codes[code] = true
+				}
+			}
+		}
+	}
+	return codes
+}
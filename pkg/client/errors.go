@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+//ErrorCode identifies the error returned by the API. It is an error itself so a returned *Error
+//can be compared with errors.Is(err, client.InvalidTicket)
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+//Error codes of the tracking API
+const (
+	BadRequest    ErrorCode = "ERR_API_BAD_REQUEST"
+	InvalidTicket ErrorCode = "ERR_API_INVALID_TICKET"
+	UnableToTrack ErrorCode = "ERR_API_UNABLE_TO_TRACK"
+	RateLimited   ErrorCode = "ERR_API_RATE_LIMITED"
+	Unauthorized  ErrorCode = "ERR_API_UNAUTHORIZED"
+	Forbidden     ErrorCode = "ERR_API_FORBIDDEN"
+	InvalidTenant ErrorCode = "ERR_API_INVALID_TENANT"
+	Internal      ErrorCode = "ERR_API_INTERNAL"
+	Timeout       ErrorCode = "ERR_API_TIMEOUT"
+	ClientClosed  ErrorCode = "ERR_API_CLIENT_CLOSED_REQUEST"
+)
+
+//ValidationError describes one problem found in a ticket, Ticket is the index of the ticket in the request
+type ValidationError struct {
+	Ticket int    `json:"ticket"`
+	Field  string `json:"field,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+//Error is an error response of the API
+type Error struct {
+	Status    int               `json:"status"`
+	Code      ErrorCode         `json:"error_code"`
+	Message   string            `json:"error_message"`
+	Errors    []ValidationError `json:"errors"`
+	RequestID string            `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("flight paths tracker: %d %s (request %s)", e.Status, http.StatusText(e.Status), e.RequestID)
+	}
+	return fmt.Sprintf("flight paths tracker: %s: %s (request %s)", e.Code, e.Message, e.RequestID)
+}
+
+//Is matches the ErrorCode of the error
+func (e *Error) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code == e.Code
+}
@@ -0,0 +1,59 @@
+package flightpath
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	//ErrInvalidTicket is matched by the InvalidTicketsError returned by Validate
+	ErrInvalidTicket = errors.New("invalid ticket")
+	//ErrUnableToTrack is returned when the tickets do not form a single itinerary
+	ErrUnableToTrack = errors.New("unable to track source and destination for the given tickets")
+)
+
+//Reason explains why a ticket field is invalid
+type Reason string
+
+const (
+	ReasonWrongLength    Reason = "WRONG_LENGTH"
+	ReasonLowercase      Reason = "LOWERCASE"
+	ReasonNonAlpha       Reason = "NON_ALPHA"
+	ReasonUnknownAirport Reason = "UNKNOWN_AIRPORT"
+	ReasonSelfLoop       Reason = "SELF_LOOP"
+)
+
+//Ticket fields of a ValidationError
+const (
+	FieldOrigin      = "origin"
+	FieldDestination = "destination"
+)
+
+//ValidationError describes one problem found in a ticket, Ticket is the index of the ticket in the input
+type ValidationError struct {
+	Ticket int
+	Field  string
+	Value  string
+	Reason Reason
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("ticket %d: %s %q: %s", e.Ticket, e.Field, e.Value, e.Reason)
+}
+
+//InvalidTicketsError lists every problem found in the tickets
+type InvalidTicketsError struct {
+	Errors []ValidationError
+}
+
+func (e *InvalidTicketsError) Error() string {
+	if len(e.Errors) == 0 {
+		return ErrInvalidTicket.Error()
+	}
+	return fmt.Sprintf("%s: %d problems found, first %s", ErrInvalidTicket, len(e.Errors), e.Errors[0])
+}
+
+//Is makes errors.Is(err, ErrInvalidTicket) true
+func (e *InvalidTicketsError) Is(target error) bool {
+	return target == ErrInvalidTicket
+}
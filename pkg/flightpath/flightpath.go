@@ -0,0 +1,141 @@
+//Package flightpath finds where a traveller started and ended from their unordered flight tickets.
+//It has no dependency on the HTTP API and can be used directly by other programs
+package flightpath
+
+import (
+	"context"
+	"strings"
+)
+
+//checkInterval is the number of tickets processed between two checks of the context
+const checkInterval = 1000
+
+//Ticket is a single flight between two airports, given by their IATA codes
+type Ticket struct {
+	Origin      string
+	Destination string
+}
+
+//Itinerary is the first origin and last destination of a set of tickets
+type Itinerary struct {
+	Source      string
+	Destination string
+}
+
+//Airports tells which airport codes exist
+type Airports interface {
+	Known(code string) bool
+}
+
+type Tracker interface {
+	//Validate returns an *InvalidTicketsError listing every problem of the tickets, or the error of the context
+	Validate(ctx context.Context, tickets []Ticket) error
+	//Track returns the itinerary of valid tickets, ErrUnableToTrack when they do not form one,
+	//or the error of the context
+	Track(ctx context.Context, tickets []Ticket) (Itinerary, error)
+}
+
+type tracker struct {
+	airports Airports
+}
+
+//NewTracker checks airport codes against the airports, any well formed code is accepted when it is nil
+func NewTracker(airports Airports) Tracker {
+	return &tracker{
+		airports: airports,
+	}
+}
+
+func (t *tracker) Track(ctx context.Context, tickets []Ticket) (Itinerary, error) {
+	flightPath := make(map[string]int)
+	for i, ticket := range tickets {
+		if err := checkContext(ctx, i); err != nil {
+			return Itinerary{}, err
+		}
+		flightPath[ticket.Origin]--
+		flightPath[ticket.Destination]++
+	}
+
+	//every airport but the source and the destination is left as often as it is reached
+	checked := 0
+	for airport, balance := range flightPath {
+		if err := checkContext(ctx, checked); err != nil {
+			return Itinerary{}, err
+		}
+		checked++
+		if balance > 1 || balance < -1 {
+			return Itinerary{}, ErrUnableToTrack
+		}
+		if balance == 0 {
+			delete(flightPath, airport)
+		}
+	}
+	if len(flightPath) != 2 {
+		return Itinerary{}, ErrUnableToTrack
+	}
+
+	var itinerary Itinerary
+	for airport, balance := range flightPath {
+		switch balance {
+		case -1:
+			itinerary.Source = airport
+		case 1:
+			itinerary.Destination = airport
+		}
+	}
+	return itinerary, nil
+}
+
+func (t *tracker) Validate(ctx context.Context, tickets []Ticket) error {
+	var validationErrors []ValidationError
+	for i, ticket := range tickets {
+		if err := checkContext(ctx, i); err != nil {
+			return err
+		}
+		validationErrors = append(validationErrors, t.validatePlace(i, FieldOrigin, ticket.Origin)...)
+		validationErrors = append(validationErrors, t.validatePlace(i, FieldDestination, ticket.Destination)...)
+		//check the ticket does not fly back to where it started
+		if ticket.Origin == ticket.Destination {
+			validationErrors = append(validationErrors, ValidationError{Ticket: i, Field: FieldDestination, Value: ticket.Destination, Reason: ReasonSelfLoop})
+		}
+	}
+	if len(validationErrors) > 0 {
+		return &InvalidTicketsError{Errors: validationErrors}
+	}
+	return nil
+}
+
+//validatePlace checks the IATA code of an airport and returns every problem found
+func (t *tracker) validatePlace(ticket int, field string, place string) []ValidationError {
+	var validationErrors []ValidationError
+	addError := func(reason Reason) {
+		validationErrors = append(validationErrors, ValidationError{Ticket: ticket, Field: field, Value: place, Reason: reason})
+	}
+
+	if len(place) != 3 {
+		addError(ReasonWrongLength)
+	}
+	if strings.ToUpper(place) != place {
+		addError(ReasonLowercase)
+	}
+	if strings.IndexFunc(place, isNotASCIILetter) >= 0 {
+		addError(ReasonNonAlpha)
+	}
+	//only well formed codes are looked up in the airports
+	if len(validationErrors) == 0 && t.airports != nil && !t.airports.Known(place) {
+		addError(ReasonUnknownAirport)
+	}
+	return validationErrors
+}
+
+//checkContext returns the error of the context once every checkInterval iterations so loops stay cheap
+func checkContext(ctx context.Context, iteration int) error {
+	if iteration%checkInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
+func isNotASCIILetter(r rune) bool {
+	return (r < 'A' || r > 'Z') && (r < 'a' || r > 'z')
+}
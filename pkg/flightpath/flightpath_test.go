@@ -0,0 +1,85 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type knownAirports map[string]bool
+
+func (k knownAirports) Known(code string) bool {
+	return k[code]
+}
+
+type FlightPathTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	tracker Tracker
+}
+
+func TestFlightPath(t *testing.T) {
+	suite.Run(t, new(FlightPathTestSuite))
+}
+
+func (suite *FlightPathTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.tracker = NewTracker(nil)
+}
+
+func (suite *FlightPathTestSuite) TestTrackFindsSourceAndDestination() {
+	tickets := []Ticket{{"IND", "EWR"}, {"SFO", "ATL"}, {"GSO", "IND"}, {"ATL", "GSO"}}
+
+	itinerary, err := suite.tracker.Track(suite.ctx, tickets)
+
+	suite.Nil(err)
+	suite.Equal(Itinerary{Source: "SFO", Destination: "EWR"}, itinerary)
+}
+
+func (suite *FlightPathTestSuite) TestTrackRejectsBrokenItineraries() {
+	for _, tickets := range [][]Ticket{
+		{{"IND", "EWR"}, {"IND", "EWR"}},
+		{{"IND", "EWR"}, {"EWR", "IND"}},
+		{{"IND", "EWR"}, {"SFO", "ATL"}},
+	} {
+		_, err := suite.tracker.Track(suite.ctx, tickets)
+		suite.True(errors.Is(err, ErrUnableToTrack), "%v", tickets)
+	}
+}
+
+func (suite *FlightPathTestSuite) TestValidateReturnsEveryProblem() {
+	tracker := NewTracker(knownAirports{"SFO": true, "ATL": true})
+
+	err := tracker.Validate(suite.ctx, []Ticket{{"SFO", "ATL"}, {"sf", "ATL"}, {"EWR", "EWR"}})
+
+	suite.True(errors.Is(err, ErrInvalidTicket))
+	var invalidTickets *InvalidTicketsError
+	suite.Require().True(errors.As(err, &invalidTickets))
+	suite.Equal([]ValidationError{
+		{Ticket: 1, Field: FieldOrigin, Value: "sf", Reason: ReasonWrongLength},
+		{Ticket: 1, Field: FieldOrigin, Value: "sf", Reason: ReasonLowercase},
+		{Ticket: 2, Field: FieldOrigin, Value: "EWR", Reason: ReasonUnknownAirport},
+		{Ticket: 2, Field: FieldDestination, Value: "EWR", Reason: ReasonUnknownAirport},
+		{Ticket: 2, Field: FieldDestination, Value: "EWR", Reason: ReasonSelfLoop},
+	}, invalidTickets.Errors)
+}
+
+func (suite *FlightPathTestSuite) TestEmptyInvalidTicketsErrorHasMessage() {
+	suite.Equal(ErrInvalidTicket.Error(), (&InvalidTicketsError{}).Error())
+}
+
+func (suite *FlightPathTestSuite) TestValidateAcceptsValidTickets() {
+	suite.Nil(suite.tracker.Validate(suite.ctx, []Ticket{{"SFO", "ATL"}, {"ATL", "GSO"}}))
+}
+
+func (suite *FlightPathTestSuite) TestStopsWhenContextIsDone() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+	tickets := []Ticket{{"SFO", "ATL"}}
+
+	suite.Equal(context.Canceled, suite.tracker.Validate(ctx, tickets))
+	_, err := suite.tracker.Track(ctx, tickets)
+	suite.Equal(context.Canceled, err)
+}